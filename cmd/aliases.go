@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// builtinAliases are logical model names that follow whatever concrete
+// model is current, so scripts can say --model fast instead of pinning an
+// ID. The config file's aliases section adds to and overrides them.
+var builtinAliases = map[string]map[string]string{
+	// OpenAI sends requests with images to its vision model whatever
+	// --model says, so a vision alias would have no effect.
+	"openai": {
+		"fast":  "gpt-4o-mini",
+		"smart": "gpt-4.1",
+	},
+	"bedrock": {
+		"fast":   "anthropic.claude-3-haiku-20240307-v1:0",
+		"smart":  "anthropic.claude-3-5-sonnet-20240620-v1:0",
+		"vision": "anthropic.claude-3-5-sonnet-20240620-v1:0",
+	},
+	"cohere": {
+		"fast":  "command-r7b-12-2024",
+		"smart": "command-a-03-2025",
+	},
+	"deepseek": {
+		"fast":  "deepseek-chat",
+		"smart": "deepseek-reasoner",
+	},
+	"openrouter": {
+		"fast":   "openai/gpt-4o-mini",
+		"smart":  "anthropic/claude-3.5-sonnet",
+		"vision": "openai/gpt-4o",
+	},
+	"xai": {
+		"fast":   "grok-3-mini",
+		"smart":  "grok-4",
+		"vision": "grok-2-vision-latest",
+	},
+	"together": {
+		"fast":   "meta-llama/Llama-3.2-3B-Instruct-Turbo",
+		"smart":  "meta-llama/Llama-3.3-70B-Instruct-Turbo",
+		"vision": "meta-llama/Llama-3.2-11B-Vision-Instruct-Turbo",
+		"code":   "Qwen/Qwen2.5-Coder-32B-Instruct",
+	},
+	"mistral": {
+		"fast":   "mistral-small-latest",
+		"smart":  "mistral-large-latest",
+		"vision": "pixtral-large-latest",
+		"code":   "codestral-latest",
+	},
+}
+
+// resolveModelAlias returns the model ID an alias stands for on provider,
+// or model unchanged when it isn't an alias.
+func resolveModelAlias(provider, model string) string {
+	if model == "" {
+		return ""
+	}
+	resolved, ok := appConfig.Aliases[provider][model]
+	if !ok {
+		resolved, ok = builtinAliases[provider][model]
+	}
+	if !ok {
+		return model
+	}
+	if debugFlag {
+		fmt.Fprintf(os.Stderr, "[DEBUG] %s: model alias %q resolved to %s\n", provider, model, resolved)
+	}
+	return resolved
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	apiKeyFileFlag    string
+	apiKeyCommandFlag string
+)
+
+// resolveAPIKeyFlag fills apiKeyFlag from --apikey-command or --apikey-file
+// so keys can come from a secret manager instead of the environment or the
+// shell history. Precedence: --apikey > --apikey-command > --apikey-file >
+// environment variable (applied later by getAPIKey).
+func resolveAPIKeyFlag(ctx context.Context) error {
+	switch {
+	case apiKeyFlag != "":
+		return nil
+	case apiKeyCommandFlag != "":
+		key, err := apiKeyFromCommand(ctx, apiKeyCommandFlag)
+		if err != nil {
+			return err
+		}
+		apiKeyFlag = key
+	case apiKeyFileFlag != "":
+		data, err := os.ReadFile(apiKeyFileFlag)
+		if err != nil {
+			return fmt.Errorf("failed to read --apikey-file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return fmt.Errorf("--apikey-file %s is empty", apiKeyFileFlag)
+		}
+		apiKeyFlag = key
+	}
+	return nil
+}
+
+// apiKeyFromCommand runs command through the shell, e.g. "pass show openai",
+// and returns the first line of its output.
+func apiKeyFromCommand(ctx context.Context, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("--apikey-command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("--apikey-command failed: %w", err)
+	}
+
+	// Tools like pass keep metadata after the first line.
+	key, _, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("--apikey-command printed no key")
+	}
+	return key, nil
+}
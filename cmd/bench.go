@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ai-cli/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchPrompt    string
+	benchProviders []string
+	benchRuns      int
+	benchJson      bool
+	benchFormat    string
+)
+
+type BenchResult struct {
+	Provider        string  `json:"provider" yaml:"provider" xml:"provider"`
+	Runs            int     `json:"runs" yaml:"runs" xml:"runs"`
+	Errors          int     `json:"errors" yaml:"errors" xml:"errors"`
+	MinMs           int64   `json:"min_ms" yaml:"min_ms" xml:"min_ms"`
+	MedianMs        int64   `json:"median_ms" yaml:"median_ms" xml:"median_ms"`
+	P95Ms           int64   `json:"p95_ms" yaml:"p95_ms" xml:"p95_ms"`
+	MaxMs           int64   `json:"max_ms" yaml:"max_ms" xml:"max_ms"`
+	TokensPerSecond float64 `json:"tokens_per_second" yaml:"tokens_per_second" xml:"tokens_per_second"`
+	LastError       string  `json:"last_error,omitempty" yaml:"last_error,omitempty" xml:"last_error,omitempty"`
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure provider latency and throughput for a prompt",
+	Long: `Send the same prompt --runs times to each provider and report total
+latency (min/median/p95/max) and output tokens per second.
+
+Output tokens are the completion tokens the provider reports, or, when it
+reports none, are counted locally with the same tokenizer as the tokens
+command. Time-to-first-token is not measured because requests are not
+streamed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		_, _ = loadDotenv()
+
+		format, err := resolveFormat(benchFormat, benchJson)
+		if err != nil {
+			return err
+		}
+		if benchRuns < 1 {
+			return &usageError{err: fmt.Errorf("--runs must be at least 1")}
+		}
+
+		names := benchProviders
+		if len(names) == 0 {
+			if err := resolveDefaultProvider(cmd); err != nil {
+				return &usageError{err: err}
+			}
+			names = []string{providerFlag}
+		}
+
+		var results []BenchResult
+		for _, name := range names {
+			name = strings.ToLower(name)
+			provider, err := getProvider(name, "", "")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+				results = append(results, BenchResult{Provider: name, LastError: err.Error()})
+				continue
+			}
+			provider = providers.Chain(provider, providerMiddlewares(name, nil)...)
+			results = append(results, benchProvider(cmd, name, provider))
+		}
+
+		if format != formatText {
+			data, _ := marshalStructured(format, results)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
+			return nil
+		}
+		printBenchTable(results)
+		return nil
+	},
+}
+
+func benchProvider(cmd *cobra.Command, name string, provider providers.Provider) BenchResult {
+	result := BenchResult{Provider: name, Runs: benchRuns}
+	inputs := providers.Inputs{Prompt: benchPrompt}
+
+	var latencies []time.Duration
+	var totalTokens int
+	var totalTime time.Duration
+
+	for i := 0; i < benchRuns; i++ {
+		if cmd.Context().Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		out, err := provider.Generate(cmd.Context(), inputs)
+		elapsed := time.Since(start)
+
+		if err != nil {
+			result.Errors++
+			result.LastError = err.Error()
+			fmt.Fprintf(os.Stderr, "%s run %d/%d failed: %v\n", name, i+1, benchRuns, err)
+			continue
+		}
+
+		latencies = append(latencies, elapsed)
+		totalTokens += outputTokens(out)
+		totalTime += elapsed
+	}
+
+	if len(latencies) == 0 {
+		return result
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.MinMs = latencies[0].Milliseconds()
+	result.MedianMs = percentile(latencies, 50).Milliseconds()
+	result.P95Ms = percentile(latencies, 95).Milliseconds()
+	result.MaxMs = latencies[len(latencies)-1].Milliseconds()
+	if totalTime > 0 {
+		result.TokensPerSecond = math.Round(float64(totalTokens)/totalTime.Seconds()*10) / 10
+	}
+	return result
+}
+
+// outputTokens returns the completion tokens the provider reported, or a
+// local count with the served model's tokenizer when it reported none.
+func outputTokens(out providers.Result) int {
+	if out.Usage != nil && out.Usage.CompletionTokens > 0 {
+		return out.Usage.CompletionTokens
+	}
+	tokens, _ := providers.CountTokens(out.Model, out.Content)
+	return tokens
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func printBenchTable(results []BenchResult) {
+	fmt.Println("┌──────────────┬────────┬────────┬──────────┬──────────┬──────────┬──────────┬──────────┐")
+	fmt.Println("│ Provider     │ Runs   │ Errors │ Min      │ Median   │ P95      │ Max      │ Tok/s    │")
+	fmt.Println("├──────────────┼────────┼────────┼──────────┼──────────┼──────────┼──────────┼──────────┤")
+	for _, r := range results {
+		fmt.Printf("│ %-12s │ %-6d │ %-6d │ %-8s │ %-8s │ %-8s │ %-8s │ %-8.1f │\n",
+			truncate(r.Provider, 12), r.Runs, r.Errors,
+			fmt.Sprintf("%dms", r.MinMs), fmt.Sprintf("%dms", r.MedianMs),
+			fmt.Sprintf("%dms", r.P95Ms), fmt.Sprintf("%dms", r.MaxMs),
+			r.TokensPerSecond)
+	}
+	fmt.Println("└──────────────┴────────┴────────┴──────────┴──────────┴──────────┴──────────┴──────────┘")
+}
+
+func init() {
+	benchCmd.Flags().StringVarP(&benchPrompt, "prompt", "p", "", "Prompt to send on every run (required)")
+	benchCmd.Flags().StringSliceVar(&benchProviders, "provider", []string{}, "Comma-separated list of providers to benchmark (default openai, or AI_CLI_DEFAULT_PROVIDER)")
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 5, "Number of requests per provider")
+	benchCmd.Flags().BoolVar(&traceFlag, "trace", false, "Log DNS, connect, TLS and connection reuse for each request to stderr")
+	benchCmd.Flags().BoolVar(&benchJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	benchCmd.Flags().StringVar(&benchFormat, "format", formatText, "Output format (text|json|yaml|xml)")
+
+	benchCmd.MarkFlagRequired("prompt")
+	rootCmd.AddCommand(benchCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"ai-cli/internal/providers"
+)
+
+func TestOutputTokens(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog."
+	exact, ok := providers.CountTokens("gpt-4o", text)
+	if !ok {
+		t.Fatal("gpt-4o has no tokenizer")
+	}
+
+	tests := []struct {
+		name string
+		out  providers.Result
+		want int
+	}{
+		{"reported usage", providers.Result{Content: text, Model: "gpt-4o", Usage: &providers.Usage{CompletionTokens: 42}}, 42},
+		{"no usage, known model", providers.Result{Content: text, Model: "gpt-4o"}, exact},
+		{"zero usage, known model", providers.Result{Content: text, Model: "gpt-4o", Usage: &providers.Usage{}}, exact},
+		{"no usage, unknown model", providers.Result{Content: text, Model: "mistral-small-latest"}, providers.EstimateTokens(text)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := outputTokens(tc.out); got != tc.want {
+				t.Errorf("outputTokens = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBenchDefaultProvider(t *testing.T) {
+	t.Setenv("AI_CLI_DEFAULT_PROVIDER", "nosuch")
+	defer func(p string) { providerFlag = p }(providerFlag)
+
+	benchPrompt, benchRuns = "hi", 1
+	defer func() { benchPrompt, benchRuns = "", 5 }()
+
+	// An unknown default provider is rejected, so bench consulted it.
+	if err := benchCmd.RunE(benchCmd, nil); err == nil {
+		t.Fatal("bench ignored AI_CLI_DEFAULT_PROVIDER")
+	}
+	if providerFlag != "nosuch" {
+		t.Errorf("providerFlag = %q, want AI_CLI_DEFAULT_PROVIDER's value", providerFlag)
+	}
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatCitations renders the sources of a web-grounded answer as a
+// numbered list matching the [n] markers in its text, or "" when there are
+// none.
+func formatCitations(citations []string) string {
+	if len(citations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nSources:\n")
+	for i, url := range citations {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, url)
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"ai-cli/internal/providers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the optional YAML configuration file:
+//
+//	aliases:
+//	  openai:
+//	    fast: gpt-4o-mini
+//	profiles:
+//	  coding:
+//	    provider: mistral
+//	    model: code
+//	    temperature: 0.2
+//	vllm:
+//	  best_of: 4
+//	  use_beam_search: true
+type fileConfig struct {
+	// Aliases maps provider → alias → model ID, on top of builtinAliases.
+	Aliases map[string]map[string]string `yaml:"aliases"`
+	// Profiles are the presets --profile selects.
+	Profiles map[string]profile `yaml:"profiles"`
+	// VLLM is sent with every --provider vllm request.
+	VLLM providers.VLLMOptions `yaml:"vllm"`
+}
+
+// appConfig is loaded once by the root command before any subcommand runs.
+var appConfig fileConfig
+
+// configPath returns AI_CLI_CONFIG, or config.yaml in the user's config
+// directory (~/.config/ai-cli on Linux).
+func configPath() string {
+	if path := os.Getenv("AI_CLI_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ai-cli", "config.yaml")
+}
+
+// loadConfig reads the configuration file. A missing default file is not
+// an error, but one named by AI_CLI_CONFIG must exist.
+func loadConfig() (fileConfig, error) {
+	var cfg fileConfig
+	path := configPath()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && os.Getenv("AI_CLI_CONFIG") == "" {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
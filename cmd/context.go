@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxContextBytes bounds the combined size of --context-file contents.
+const defaultMaxContextBytes = 100_000
+
+var (
+	contextFiles    []string
+	maxContextBytes int
+)
+
+// buildContext reads each file and renders it as a labeled fenced block.
+// Once budget bytes of file content have been used, later content is cut
+// and a warning is printed naming the truncated files.
+func buildContext(paths []string, budget int) (string, error) {
+	var blocks []string
+	remaining := budget
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read context file %s: %w", path, err)
+		}
+
+		content := string(data)
+		if len(content) > remaining {
+			content = truncateUTF8(content, remaining)
+			fmt.Fprintf(os.Stderr, "Warning: context file %s truncated to %d of %d bytes (--max-context-bytes %d)\n",
+				path, len(content), len(data), budget)
+		}
+		remaining -= len(content)
+
+		blocks = append(blocks, fencedBlock(filepath.ToSlash(path), content))
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// fencedBlock labels content with its file name and fences it, using a fence
+// longer than any backtick run inside so embedded code blocks stay intact.
+func fencedBlock(name, content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("File: %s\n%s\n%s\n%s", name, fence, strings.TrimRight(content, "\n"), fence)
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
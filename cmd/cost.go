@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+
+	"ai-cli/internal/providers"
+)
+
+var confirmCostFlag float64
+
+// confirmCost estimates the cost of sending inputs to the primary provider,
+// counting the whole --max-tokens allowance as output, and above
+// --confirm-cost dollars asks for confirmation on the terminal.
+// Without a terminal to ask on it refuses instead of blocking.
+func confirmCost(inputs providers.Inputs) error {
+	model := firstNonEmpty(resolveModelAlias(providerFlag, modelFlag), providers.DefaultModel(providerFlag))
+	if len(inputs.Images) > 0 && (modelFlag == "" || providerFlag == "openai") {
+		model = providers.DefaultVisionModel(providerFlag)
+	}
+
+	text := systemFlag + inputs.Prompt
+	for _, msg := range inputs.Messages {
+		text += msg.Content
+	}
+	tokens, _ := providers.CountTokens(model, text)
+	maxOutputTokens := maxTokensFlag
+	if maxOutputTokens == 0 {
+		maxOutputTokens = providers.DefaultMaxTokens
+	}
+	for _, img := range inputs.Images {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data)); err == nil {
+			tokens += providers.EstimateVisionTokens(cfg.Width, cfg.Height, inputs.ImageDetail)
+		}
+	}
+
+	cost, ok := providers.EstimateCost(model, tokens, maxOutputTokens)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Warning: no price known for model %q; --confirm-cost can't check this request\n", model)
+		return nil
+	}
+	if cost <= confirmCostFlag {
+		return nil
+	}
+
+	summary := fmt.Sprintf("estimated cost $%.4f (%d input + up to %d output tokens on %s) exceeds --confirm-cost $%.4f",
+		cost, tokens, maxOutputTokens, model, confirmCostFlag)
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return fmt.Errorf("%s and there is no terminal to confirm on", summary)
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(os.Stderr, "%s. Send anyway? [y/N] ", summary)
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("aborted: %s", summary)
+}
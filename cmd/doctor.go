@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-cli/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkOK   = "ok"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+var (
+	doctorProviders []string
+	doctorTimeout   time.Duration
+	doctorJson      bool
+	doctorFormat    string
+)
+
+type DoctorCheck struct {
+	Name   string `json:"name" yaml:"name" xml:"name"`
+	Status string `json:"status" yaml:"status" xml:"status"`
+	Detail string `json:"detail" yaml:"detail" xml:"detail"`
+	Hint   string `json:"hint,omitempty" yaml:"hint,omitempty" xml:"hint,omitempty"`
+}
+
+type DoctorReport struct {
+	OK     bool          `json:"ok" yaml:"ok" xml:"ok"`
+	Checks []DoctorCheck `json:"checks" yaml:"checks" xml:"check"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose API keys, .env, network access and authentication",
+	Long: `Check the local setup and print a checklist with a hint for each problem.
+
+For every provider doctor reports whether its API key is set, whether its
+API endpoint can be reached, and whether the key is accepted, using the same
+cheap /models request as ping. It also reports whether .env and the config
+file were found. Providers without a key are skipped unless none has one.
+The exit code is 1 when any check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, err := resolveFormat(doctorFormat, doctorJson)
+		if err != nil {
+			return err
+		}
+
+		names := doctorProviders
+		if len(names) == 0 {
+			names = providers.Defaults()
+		}
+		for i, name := range names {
+			names[i] = strings.ToLower(name)
+			if !slices.Contains(doctorProviderNames(), names[i]) {
+				return &usageError{err: fmt.Errorf("doctor can't check provider %q (choose from %s)", name, strings.Join(doctorProviderNames(), ", "))}
+			}
+		}
+
+		report := DoctorReport{Checks: []DoctorCheck{checkDotenv(), checkConfigFile()}}
+
+		perProvider := make([][]DoctorCheck, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				perProvider[i] = checkProvider(cmd.Context(), name)
+			}(i, name)
+		}
+		wg.Wait()
+
+		// One missing key is normal; having none at all is the problem.
+		haveKey := false
+		for _, checks := range perProvider {
+			haveKey = haveKey || checks[0].Status == checkOK
+		}
+		for _, checks := range perProvider {
+			if !haveKey {
+				checks[0].Status = checkFail
+			}
+			report.Checks = append(report.Checks, checks...)
+		}
+
+		failed := 0
+		for _, check := range report.Checks {
+			if check.Status == checkFail {
+				failed++
+			}
+		}
+		report.OK = failed == 0
+
+		if format != formatText {
+			data, _ := marshalStructured(format, report)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
+		} else {
+			printDoctorReport(report)
+		}
+
+		if failed > 0 {
+			return &silentError{err: fmt.Errorf("doctor found %d problem(s)", failed)}
+		}
+		return nil
+	},
+}
+
+// doctorProviderNames lists the providers doctor knows how to check: hosted
+// APIs with a key and a /models endpoint.
+func doctorProviderNames() []string {
+	var names []string
+	for _, name := range providers.Names() {
+		reg, _ := providers.Lookup(name)
+		if reg.APIKeyEnv != "" && !reg.SelfHosted && reg.DefaultBaseURL != "" && reg.ListsModels() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkDotenv loads .env the way every other command does and reports what
+// happened.
+func checkDotenv() DoctorCheck {
+	check := DoctorCheck{Name: ".env"}
+	wd, _ := os.Getwd()
+	path := filepath.Join(wd, ".env")
+
+	skipped, err := loadDotenv()
+	switch {
+	case skipped:
+		check.Status, check.Detail = checkSkip, "loading disabled by --no-dotenv or AI_CLI_NO_DOTENV"
+	case errors.Is(err, fs.ErrNotExist):
+		check.Status, check.Detail = checkSkip, "no .env in "+wd+" (optional)"
+	case err != nil:
+		check.Status, check.Detail = checkFail, fmt.Sprintf("failed to read %s: %v", path, err)
+		check.Hint = "use KEY=value lines and quote values containing spaces or #"
+	default:
+		check.Status, check.Detail = checkOK, "loaded "+path
+	}
+	return check
+}
+
+func checkConfigFile() DoctorCheck {
+	check := DoctorCheck{Name: "config file"}
+	path := configPath()
+	if path == "" {
+		check.Status, check.Detail = checkSkip, "no user config directory; set AI_CLI_CONFIG to use one"
+		return check
+	}
+	// A broken file already failed in the root command, so it parsed.
+	if _, err := os.Stat(path); err != nil {
+		check.Status, check.Detail = checkSkip, "none at "+path+" (optional)"
+		return check
+	}
+	check.Status, check.Detail = checkOK, "loaded "+path
+	return check
+}
+
+// checkProvider returns the key, network and auth checks for name, in
+// that order.
+func checkProvider(ctx context.Context, name string) []DoctorCheck {
+	reg, _ := providers.Lookup(name)
+	envVar := reg.APIKeyEnv
+	key := os.Getenv(envVar)
+
+	keyCheck := DoctorCheck{Name: name + " API key", Status: checkOK, Detail: envVar + " is set"}
+	if key == "" {
+		keyCheck.Status, keyCheck.Detail = checkSkip, envVar+" is not set"
+		keyCheck.Hint = fmt.Sprintf("export %s=... or add it to .env", envVar)
+	}
+
+	netCheck := checkReachable(ctx, name)
+
+	authCheck := DoctorCheck{Name: name + " auth", Status: checkSkip}
+	switch {
+	case key == "":
+		authCheck.Detail = "no API key to test"
+	case netCheck.Status != checkOK:
+		authCheck.Detail = "endpoint unreachable"
+	default:
+		authCheck = checkAuth(ctx, name, key)
+	}
+	return []DoctorCheck{keyCheck, netCheck, authCheck}
+}
+
+// checkReachable treats any HTTP response from the provider's endpoint,
+// even an error status, as proof the network path works.
+func checkReachable(ctx context.Context, name string) DoctorCheck {
+	url := providers.DefaultBaseURL(name)
+	check := DoctorCheck{Name: name + " network"}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/models", nil)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		check.Status, check.Detail = checkFail, fmt.Sprintf("%s unreachable: %v", url, err)
+		check.Hint = "check your connection, DNS, firewall and HTTPS_PROXY settings"
+		return check
+	}
+	resp.Body.Close()
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s answered in %dms", url, time.Since(start).Milliseconds())
+	return check
+}
+
+func checkAuth(ctx context.Context, name, key string) DoctorCheck {
+	check := DoctorCheck{Name: name + " auth"}
+	lister, err := getModelLister(name, key)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	models, err := lister.ListModels(ctx)
+	if err == nil {
+		check.Status, check.Detail = checkOK, fmt.Sprintf("key accepted (%d models available)", len(models))
+		return check
+	}
+
+	check.Status, check.Detail = checkFail, err.Error()
+	reg, _ := providers.Lookup(name)
+	var apiErr *providers.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.IsAuth():
+		check.Hint = fmt.Sprintf("the key was rejected; check %s for typos or a key from another provider, or create a new one", reg.APIKeyEnv)
+	case errors.As(err, &apiErr) && apiErr.IsQuotaExceeded():
+		check.Hint = "the key works but the account is out of credit; check billing"
+	default:
+		check.Hint = "run `ai-cli ping --provider " + name + "` again later, or check the provider's status page"
+	}
+	return check
+}
+
+func printDoctorReport(report DoctorReport) {
+	for _, check := range report.Checks {
+		mark := "-"
+		switch check.Status {
+		case checkOK:
+			mark = checkMark(true)
+		case checkFail:
+			mark = checkMark(false)
+		}
+		fmt.Printf("%s %s: %s\n", mark, check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("    hint: %s\n", check.Hint)
+		}
+	}
+}
+
+func init() {
+	doctorCmd.Flags().StringSliceVar(&doctorProviders, "provider", []string{}, "Comma-separated list of providers to check (default "+strings.Join(providers.Defaults(), ",")+")")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 5*time.Second, "Timeout for each network request")
+	doctorCmd.Flags().BoolVar(&doctorJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	doctorCmd.Flags().StringVar(&doctorFormat, "format", formatText, "Output format (text|json|yaml|xml)")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var editFlag bool
+
+// editPrompt opens $VISUAL or $EDITOR (vi if neither is set) on a temp file
+// seeded with initial and returns what the user saved, like git commit does.
+// An editor that exits non-zero or an empty file aborts the request.
+func editPrompt(initial string) (string, error) {
+	editor := firstNonEmpty(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+
+	file, err := os.CreateTemp("", "ai-cli-prompt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	_, err = file.WriteString(initial)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+
+	// Run through the shell so editors with arguments ("code --wait") work.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	// Piped stdin is the document; the editor still needs the terminal.
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		cmd.Stdin = tty
+	}
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("aborting: editor %q failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	prompt := strings.TrimRight(string(data), "\r\n")
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("aborting: prompt is empty")
+	}
+	return prompt, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeText converts data from the named encoding to UTF-8. The default,
+// UTF-8, passes the bytes through untouched apart from dropping a leading BOM.
+// Other encodings accept any WHATWG label (utf-16le, windows-1252,
+// iso-8859-1, shift_jis, ...), and a byte order mark in the data wins over
+// the label, which is what Windows tools usually write.
+func decodeText(data []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf-8", "utf8":
+		return string(bytes.TrimPrefix(data, utf8BOM)), nil
+	}
+
+	enc, err := htmlindex.Get(encoding)
+	if err != nil {
+		return "", &usageError{err: fmt.Errorf("unsupported encoding %q", encoding)}
+	}
+
+	decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s text: %w", encoding, err)
+	}
+	return string(decoded), nil
+}
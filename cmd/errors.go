@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"ai-cli/internal/providers"
+)
+
+// Exit codes let scripts tell failure classes apart.
+const (
+	exitFailure   = 1
+	exitUsage     = 2
+	exitAuth      = 3
+	exitRateLimit = 4
+	exitNetwork   = 5
+	exitCancelled = 130 // conventional code for a process stopped by SIGINT
+)
+
+// usageError marks errors caused by invalid flags or arguments.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// silentError carries a failure that has already been reported to the user,
+// e.g. inside a JSON document, so Execute only turns it into an exit code.
+type silentError struct {
+	err error
+}
+
+func (e *silentError) Error() string { return e.err.Error() }
+func (e *silentError) Unwrap() error { return e.err }
+
+func exitCode(err error) int {
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
+	}
+
+	var apiErr *providers.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.IsAuth():
+			return exitAuth
+		case apiErr.IsRateLimit():
+			return exitRateLimit
+		}
+		return exitFailure
+	}
+
+	if isNetworkError(err) {
+		return exitNetwork
+	}
+
+	return exitFailure
+}
+
+// errorHint suggests what to do about failures the error text alone doesn't
+// explain, or returns "".
+func errorHint(err error) string {
+	var apiErr *providers.APIError
+	if errors.As(err, &apiErr) && apiErr.IsQuotaExceeded() {
+		return "the account is out of quota; check your billing and usage limits with the provider (retrying won't help)"
+	}
+	return ""
+}
+
+// isNetworkError reports transport failures and timeouts. http.Client wraps
+// all of those in *url.Error; matching net.Error instead would also catch the
+// syscall.Errno behind unrelated file errors.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"ai-cli/internal/providers"
+)
+
+var (
+	saveExchangeFlag string
+
+	// exchangeRecorder collects the HTTP traffic for --save-exchange; nil
+	// when the flag is unset.
+	exchangeRecorder *providers.ExchangeRecorder
+)
+
+// saveExchanges writes every recorded request and response to path as one
+// JSON document. It runs whether or not generation succeeded, since failed
+// requests are the ones worth attaching to a bug report.
+func saveExchanges(path string) {
+	exchanges := exchangeRecorder.Exchanges()
+	if exchanges == nil {
+		exchanges = []providers.Exchange{}
+	}
+	data, err := json.MarshalIndent(struct {
+		Exchanges []providers.Exchange `json:"exchanges"`
+	}{exchanges}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0o600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save exchange to %s: %v\n", path, err)
+	}
+}
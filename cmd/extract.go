@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type codeBlock struct {
+	Lang string
+	Code string
+}
+
+// languageExtensions maps common fence language hints to file extensions.
+var languageExtensions = map[string]string{
+	"go":         "go",
+	"python":     "py",
+	"py":         "py",
+	"javascript": "js",
+	"js":         "js",
+	"typescript": "ts",
+	"ts":         "ts",
+	"bash":       "sh",
+	"sh":         "sh",
+	"shell":      "sh",
+	"json":       "json",
+	"yaml":       "yaml",
+	"yml":        "yaml",
+	"rust":       "rs",
+	"java":       "java",
+	"c":          "c",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"html":       "html",
+	"css":        "css",
+	"sql":        "sql",
+	"markdown":   "md",
+	"md":         "md",
+}
+
+// extractCodeBlocks returns the fenced (``` or ~~~) code blocks in text in
+// order of appearance. An unterminated block runs to the end of the text.
+func extractCodeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if current == nil {
+			if marker := fenceMarker(trimmed); marker != "" {
+				fence = marker
+				fields := strings.Fields(strings.TrimPrefix(trimmed, marker))
+				current = &codeBlock{}
+				if len(fields) > 0 {
+					current.Lang = strings.ToLower(fields[0])
+				}
+				lines = nil
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.Code = strings.Join(lines, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+
+	if current != nil {
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// fenceMarker returns the opening fence run (``` or ~~~, three or more) that
+// starts line, or "" if the line doesn't open a fence.
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, ch))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// applyExtraction replaces content with its code blocks (the first one, or
+// all of them with --all) and optionally writes each block to --extract-dir.
+func applyExtraction(content string) (string, error) {
+	blocks := extractCodeBlocks(content)
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("no fenced code block found in response")
+	}
+	if !extractAllFlag {
+		blocks = blocks[:1]
+	}
+
+	if extractDirFlag != "" {
+		if err := writeCodeBlocks(extractDirFlag, blocks); err != nil {
+			return "", err
+		}
+	}
+
+	codes := make([]string, len(blocks))
+	for i, b := range blocks {
+		codes[i] = b.Code
+	}
+	return strings.Join(codes, "\n\n"), nil
+}
+
+func writeCodeBlocks(dir string, blocks []codeBlock) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for i, b := range blocks {
+		ext, ok := languageExtensions[b.Lang]
+		if !ok {
+			ext = "txt"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("block_%d.%s", i+1, ext))
+		if err := os.WriteFile(path, []byte(b.Code+"\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"ai-cli/internal/providers"
+)
+
+// generateWithFallback tries each named provider in order and returns the
+// first successful result together with the provider that served it. The
+// next provider is only tried when the failure is one another provider could
+// plausibly avoid (outage, throttling, rejected credentials); bad input is
+// returned straight away. notes describe every fallback that happened.
+func generateWithFallback(ctx context.Context, names []string, inputs providers.Inputs, metrics *providers.Metrics) (result providers.Result, served string, notes []string, err error) {
+	var lastErr error
+
+	for i, name := range names {
+		primary := i == 0
+
+		// --apikey and --model belong to the primary provider; fallbacks use
+		// their env vars and default models.
+		flagKey, model := "", ""
+		if primary {
+			flagKey, model = apiKeyFlag, modelFlag
+		}
+
+		provider, err := getProvider(name, flagKey, model)
+		if err != nil {
+			if primary {
+				return providers.Result{}, "", notes, fmt.Errorf("provider setup failed: %w", err)
+			}
+			notes = append(notes, fmt.Sprintf("skipping fallback %s: %v", name, err))
+			continue
+		}
+		provider = providers.Chain(provider, providerMiddlewares(name, metrics)...)
+
+		if err := validateCapabilities(provider, inputs); err != nil {
+			if primary {
+				return providers.Result{}, "", notes, err
+			}
+			notes = append(notes, fmt.Sprintf("skipping fallback %s: %v", name, err))
+			continue
+		}
+
+		result, err := provider.Generate(ctx, inputs)
+		if err != nil && model != "" && modelFallback && isModelNotFound(err) {
+			var note string
+			result, note, err = retryWithDefaultModel(ctx, name, flagKey, model, inputs, metrics, err)
+			notes = append(notes, note)
+		}
+		if err == nil {
+			return result, name, notes, nil
+		}
+		lastErr = err
+
+		if !shouldFallback(ctx, err) {
+			return providers.Result{}, "", notes, err
+		}
+		if i < len(names)-1 {
+			notes = append(notes, fmt.Sprintf("%s failed: %v; falling back to %s", name, err, names[i+1]))
+		}
+	}
+
+	return providers.Result{}, "", notes, lastErr
+}
+
+// retryWithDefaultModel repeats a request whose model was not found using
+// the provider's default model. note names both models for the user; without
+// a usable default the original error is returned unchanged.
+func retryWithDefaultModel(ctx context.Context, name, flagKey, model string, inputs providers.Inputs, metrics *providers.Metrics, notFound error) (providers.Result, string, error) {
+	defaultModel := providers.DefaultModel(name)
+	if defaultModel == "" || defaultModel == model {
+		return providers.Result{}, fmt.Sprintf("model %s not found on %s and it has no other default model", model, name), notFound
+	}
+	note := fmt.Sprintf("model %s not found on %s; retrying with default model %s", model, name, defaultModel)
+
+	provider, err := getProvider(name, flagKey, "")
+	if err != nil {
+		return providers.Result{}, note, fmt.Errorf("provider setup failed: %w", err)
+	}
+	provider = providers.Chain(provider, providerMiddlewares(name, metrics)...)
+
+	result, err := provider.Generate(ctx, inputs)
+	return result, note, err
+}
+
+func isModelNotFound(err error) bool {
+	var apiErr *providers.APIError
+	return errors.As(err, &apiErr) && apiErr.IsModelNotFound()
+}
+
+// circuitBreakers holds one breaker per provider so every request in this
+// invocation sees the same state.
+var (
+	circuitMu       sync.Mutex
+	circuitBreakers = make(map[string]*providers.CircuitBreaker)
+)
+
+func circuitBreaker(name string) *providers.CircuitBreaker {
+	circuitMu.Lock()
+	defer circuitMu.Unlock()
+	b, ok := circuitBreakers[name]
+	if !ok {
+		b = providers.NewCircuitBreaker(circuitThreshold, circuitWindow, circuitCooldown)
+		circuitBreakers[name] = b
+	}
+	return b
+}
+
+// shouldFallback reports whether err is specific to the provider that
+// returned it rather than to the request itself.
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, providers.ErrCircuitOpen) {
+		return true
+	}
+
+	var apiErr *providers.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsAuth() || apiErr.IsRateLimit() || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return isNetworkError(err)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+var fimSuffixFlag string
+
+// fimSuffix returns the code after the gap for fill-in-the-middle requests,
+// read from a file when --fim-suffix starts with "@".
+func fimSuffix() (string, error) {
+	path, ok := strings.CutPrefix(fimSuffixFlag, "@")
+	if !ok {
+		return fimSuffixFlag, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --fim-suffix file: %w", err)
+	}
+	if len(data) == 0 {
+		return "", &usageError{err: fmt.Errorf("--fim-suffix file %s is empty", path)}
+	}
+	return string(data), nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ai-cli/internal/providers"
+)
+
+var usageFooterFlag bool
+
+// printUsageFooter writes a one-line summary of the request, e.g.
+//
+//	tokens: 123 in / 456 out / 579 total | model: gpt-4o | 1.8s
+//
+// Parts the provider didn't report are left out.
+func printUsageFooter(w io.Writer, usage *providers.Usage, model string, elapsed time.Duration) {
+	var parts []string
+	if usage != nil {
+		parts = append(parts, fmt.Sprintf("tokens: %d in / %d out / %d total",
+			usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens))
+	}
+	if model != "" {
+		parts = append(parts, "model: "+model)
+	}
+	parts = append(parts, fmt.Sprintf("%.1fs", elapsed.Seconds()))
+	fmt.Fprintln(w, strings.Join(parts, " | "))
+}
@@ -2,109 +2,457 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"ai-cli/internal/providers"
 
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
 var (
-	promptFlag   string
-	imagesFlag   []string
-	providerFlag string
-	apiKeyFlag   string
-	jsonOutput   bool
-	debugFlag    bool
+	promptFlag     string
+	promptFileFlag string
+	encodingFlag   string
+	prefixFlag     string
+	suffixFlag     string
+	imagesFlag     []string
+	providerFlag   string
+	fallbackFlag   []string
+	apiKeyFlag     string
+	orgFlag        string
+	projectFlag    string
+	caCertFlag     string
+	insecureFlag   bool
+	jsonOutput     bool
+	formatFlag     string
+	debugFlag      bool
+	metricsFile    string
+	outputFlag     string
+	watchFlag      bool
+	extractFlag    string
+	extractAllFlag bool
+	extractDirFlag string
+	timeoutFlag    int
+	maxRespFlag    int64
+	requestIDFlag  string
+	userFlag       string
+	teeFlag        string
+	redactFlag     bool
+	redactFileFlag string
+	listModelsFlag bool
+	compressFlag   bool
+	modelFlag      string
+	modelFallback  bool
+	traceFlag      bool
+	showModelFlag  bool
+	logProbsFlag   bool
+	topLogProbs    int
+	imageDetail    string
+	visionCostFlag bool
+	dumpCurlFlag   bool
+	dumpCurlUnsafe bool
+	trimFlag       bool
+	baseURLFlag    string
+	authHeaderFlag string
+	bedrockAPIFlag string
 )
 
 type CLIOutput struct {
-	Success  bool     `json:"success"`
-	Content  string   `json:"content,omitempty"`
-	Error    string   `json:"error,omitempty"`
-	Warnings []string `json:"warnings,omitempty"`
+	Success   bool     `json:"success" yaml:"success" xml:"success"`
+	Content   string   `json:"content,omitempty" yaml:"content,omitempty" xml:"content,omitempty"`
+	Error     string   `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+	Provider  string   `json:"provider,omitempty" yaml:"provider,omitempty" xml:"provider,omitempty"`
+	RequestID string   `json:"request_id,omitempty" yaml:"request_id,omitempty" xml:"request_id,omitempty"`
+	Model     string   `json:"model,omitempty" yaml:"model,omitempty" xml:"model,omitempty"`
+	Warnings  []string `json:"warnings,omitempty" yaml:"warnings,omitempty" xml:"warning,omitempty"`
+
+	// DurationMS is the wall-clock time of the request, fallbacks included.
+	DurationMS int64 `json:"duration_ms" yaml:"duration_ms" xml:"duration_ms"`
+
+	LogProbs  []providers.TokenLogProb `json:"logprobs,omitempty" yaml:"logprobs,omitempty" xml:"logprob,omitempty"`
+	Usage     *providers.Usage         `json:"usage,omitempty" yaml:"usage,omitempty" xml:"usage,omitempty"`
+	Citations []string                 `json:"citations,omitempty" yaml:"citations,omitempty" xml:"citation,omitempty"`
 }
 
 var generateCmd = &cobra.Command{
 	Use:     "generate",
 	Aliases: []string{"gen", "ask"},
 	Short:   "Generate responses using AI models",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		// Flags are valid by now; runtime failures shouldn't dump usage.
+		cmd.SilenceUsage = true
+		ctx := cmd.Context()
 		var warnings []string
 
-		if err := godotenv.Load(); err != nil {
+		format, err := resolveFormat(formatFlag, jsonOutput)
+		if err != nil {
+			return err
+		}
+		if extractFlag != "" && extractFlag != "code" {
+			return &usageError{err: fmt.Errorf("unsupported --extract value %q (code)", extractFlag)}
+		}
+		if watchFlag && promptFileFlag == "" {
+			return &usageError{err: fmt.Errorf("--watch requires --prompt-file")}
+		}
+		if watchFlag && editFlag {
+			return &usageError{err: fmt.Errorf("--edit can't be combined with --watch")}
+		}
+		if extraHeaders, err = parseHeaders(headerFlags); err != nil {
+			return &usageError{err: err}
+		}
+		if extraParams, err = parseExtraParams(extraParamsFlag); err != nil {
+			return &usageError{err: err}
+		}
+		if responseSchema, err = loadResponseSchema(responseSchemaFlag); err != nil {
+			return &usageError{err: err}
+		}
+		if messagesFileFlag != "" && fimSuffixFlag != "" {
+			return &usageError{err: fmt.Errorf("--messages-file can't be combined with --fim-suffix")}
+		}
+		if seedMessages, err = loadMessages(messagesFileFlag); err != nil {
+			return &usageError{err: err}
+		}
+		if stdinPositionFlag != stdinAfter && stdinPositionFlag != stdinBefore {
+			return &usageError{err: fmt.Errorf("unsupported --stdin-position value %q (before|after)", stdinPositionFlag)}
+		}
+		if imageDetail != "" && imageDetail != "low" && imageDetail != "high" && imageDetail != "auto" {
+			return &usageError{err: fmt.Errorf("unsupported --image-detail value %q (low|high|auto)", imageDetail)}
+		}
+		if stripThinkingFlag && (thinkingStartFlag == "" || thinkingEndFlag == "") {
+			return &usageError{err: fmt.Errorf("--thinking-start and --thinking-end must not be empty")}
+		}
+		if topLogProbs < 0 || topLogProbs > 20 {
+			return &usageError{err: fmt.Errorf("--top-logprobs must be between 0 and 20")}
+		}
+		if outputTemplateFlag != "" {
+			if format != formatText {
+				return &usageError{err: fmt.Errorf("--output-template can't be combined with --json or --format")}
+			}
+			if outputTemplate, err = parseOutputTemplate(outputTemplateFlag); err != nil {
+				return &usageError{err: err}
+			}
+		}
+
+		if skipped, err := loadDotenv(); !skipped && err != nil {
 			warnings = append(warnings, "No .env file found")
 		}
+		// Profile values count as given flags, so they also beat
+		// AI_CLI_DEFAULT_PROVIDER.
+		if err := applyProfile(cmd); err != nil {
+			return &usageError{err: err}
+		}
+		if err := validateSampling(cmd); err != nil {
+			return &usageError{err: err}
+		}
+		if err := resolveDefaultProvider(cmd); err != nil {
+			return &usageError{err: err}
+		}
+		if err := resolveAPIKeyFlag(ctx); err != nil {
+			return err
+		}
 
-		inputs, err := parseInputs()
-		if err != nil {
-			return formatOutput(jsonOutput, "", fmt.Errorf("input validation failed: %w", err), warnings)
+		var metrics *providers.Metrics
+		if metricsFile != "" {
+			metrics = providers.NewMetrics()
+			defer func() {
+				if werr := writeMetricsFile(metricsFile, metrics); werr != nil && err == nil {
+					err = werr
+				}
+			}()
 		}
 
-		provider, err := getProvider(providerFlag, apiKeyFlag)
-		if err != nil {
-			return formatOutput(jsonOutput, "", fmt.Errorf("provider setup failed: %w", err), warnings)
+		if listModelsFlag {
+			return listProviderModels(ctx, format)
 		}
 
-		if err := validateCapabilities(provider, inputs); err != nil {
-			return formatOutput(jsonOutput, "", err, warnings)
+		if watchFlag {
+			return watchFile(ctx, promptFileFlag, func() error {
+				return runGenerate(ctx, format, warnings, metrics)
+			})
 		}
+		return runGenerate(ctx, format, warnings, metrics)
+	},
+}
 
-		result, err := provider.Generate(ctx, inputs)
-		if err != nil {
-			return formatOutput(jsonOutput, "", err, warnings)
+// resolveDefaultProvider applies AI_CLI_DEFAULT_PROVIDER when --provider was
+// not given on the command line, then checks the chosen provider exists.
+func resolveDefaultProvider(cmd *cobra.Command) error {
+	source := "--provider"
+	if !cmd.Flags().Changed("provider") {
+		if env := os.Getenv("AI_CLI_DEFAULT_PROVIDER"); env != "" {
+			providerFlag, source = strings.ToLower(strings.TrimSpace(env)), "AI_CLI_DEFAULT_PROVIDER"
 		}
+	}
 
-		return formatOutput(jsonOutput, result, nil, warnings)
-	},
+	if _, ok := providers.Lookup(providerFlag); ok {
+		return nil
+	}
+	return fmt.Errorf("unknown provider %q from %s (valid: %s)", providerFlag, source, strings.Join(providers.Names(), ", "))
+}
+
+// listProviderModels prints the models of the selected provider, reusing the
+// models command's table.
+func listProviderModels(ctx context.Context, format string) error {
+	provider, err := getProvider(providerFlag, apiKeyFlag, modelFlag)
+	if err != nil {
+		return fmt.Errorf("provider setup failed: %w", err)
+	}
+
+	lister, ok := provider.(providers.ModelLister)
+	if !ok {
+		return fmt.Errorf("provider %s can't list models", providerFlag)
+	}
+
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	if format != formatText {
+		data, _ := marshalStructured(format, map[string][]providers.Model{providerFlag: models})
+		fmt.Println(strings.TrimSuffix(string(data), "\n"))
+		return nil
+	}
+	printProviderTable(providerFlag, models)
+	return nil
 }
 
-func formatOutput(jsonFlag bool, content string, err error, warnings []string) error {
-	if jsonFlag {
-		output := CLIOutput{
-			Success:  err == nil,
-			Content:  content,
-			Error:    "",
-			Warnings: warnings,
+// runGenerate performs one generate request and prints its result.
+func runGenerate(ctx context.Context, format string, warnings []string, metrics *providers.Metrics) error {
+	inputs, err := parseInputs()
+	if err != nil {
+		return formatOutput(format, CLIOutput{Warnings: warnings}, fmt.Errorf("input validation failed: %w", err))
+	}
+	if visionCostFlag && len(inputs.Images) > 0 {
+		printVisionCost(os.Stderr, inputs)
+	}
+	if confirmCostFlag > 0 {
+		if err := confirmCost(inputs); err != nil {
+			return formatOutput(format, CLIOutput{Warnings: warnings}, err)
 		}
+	}
+
+	if saveExchangeFlag != "" {
+		exchangeRecorder = &providers.ExchangeRecorder{}
+		defer saveExchanges(saveExchangeFlag)
+	}
+
+	candidates := append([]string{providerFlag}, fallbackFlag...)
+	start := time.Now()
+	result, served, notes, err := generateWithFallback(ctx, candidates, inputs, metrics)
+	elapsed := time.Since(start)
+	if format == formatText && debugFlag {
+		fmt.Fprintf(os.Stderr, "[DEBUG] generate took %dms\n", elapsed.Milliseconds())
+	}
+	warnings = append(warnings, notes...)
+	if format == formatText {
+		for _, note := range notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+	}
+
+	output := CLIOutput{
+		Content:   result.Content,
+		Provider:  served,
+		RequestID: result.RequestID,
+		Warnings:  warnings,
+		LogProbs:  result.LogProbs,
+		Usage:     result.Usage,
+		Citations: result.Citations,
+
+		DurationMS: elapsed.Milliseconds(),
+	}
+	var apiErr *providers.APIError
+	if errors.As(err, &apiErr) {
+		output.RequestID = apiErr.RequestID
+	}
+	if (showModelFlag || outputTemplate != nil) && err == nil {
+		output.Model = result.Model
+		if showModelFlag && format == formatText && result.Model != "" {
+			fmt.Fprintf(os.Stderr, "model: %s\n", result.Model)
+		}
+	}
+
+	if err == nil && stripThinkingFlag {
+		output.Content = stripThinking(output.Content, thinkingStartFlag, thinkingEndFlag)
+	}
+	// Structured outputs should already conform; validating anyway catches
+	// refusals and truncated replies, and normalizes the JSON.
+	if schemaPath := firstNonEmpty(schemaFileFlag, responseSchemaFlag); err == nil && schemaPath != "" {
+		output.Content, err = enforceSchema(ctx, schemaPath, inputs, output.Content, metrics)
+	}
+	if err == nil && extractFlag != "" {
+		output.Content, err = applyExtraction(output.Content)
+	}
+	if err == nil && trimFlag {
+		output.Content = strings.TrimSpace(output.Content)
+	}
+
+	if err := formatOutput(format, output, err); err != nil {
+		return err
+	}
+	if usageFooterFlag {
+		printUsageFooter(os.Stderr, result.Usage, firstNonEmpty(result.Model, providers.DefaultModel(served)), elapsed)
+	}
+	return nil
+}
+
+// formatOutput prints output in the requested format. Success and Error are
+// derived from err.
+func formatOutput(format string, output CLIOutput, err error) error {
+	if format != formatText {
+		output.Success = err == nil
 		if err != nil {
+			output.Content = ""
 			output.Error = err.Error()
 		}
 
-		jsonData, _ := json.Marshal(output)
-		fmt.Println(string(jsonData))
+		data, _ := marshalStructured(format, output)
+		if writeErr := writeResult(strings.TrimSuffix(string(data), "\n") + "\n"); writeErr != nil {
+			return writeErr
+		}
+		if err != nil {
+			return &silentError{err: err}
+		}
 		return nil
 	}
 
 	if err != nil {
 		return err
 	}
-	fmt.Println(content)
+	if outputTemplate != nil {
+		output.Success = true
+		rendered, err := renderOutputTemplate(outputTemplate, output)
+		if err != nil {
+			return err
+		}
+		return writeResult(rendered)
+	}
+	// Extracted code and validated JSON must stay machine-readable.
+	if extractFlag != "" || schemaFileFlag != "" || responseSchemaFlag != "" {
+		return writeResult(output.Content + "\n")
+	}
+	return writeResult(output.Content + "\n" + formatCitations(output.Citations))
+}
+
+// writeResult sends the rendered result to stdout, or to --output, and with
+// --tee to both stdout and a file. Files are written atomically so an
+// interrupted run never leaves a truncated file that looks complete.
+func writeResult(data string) error {
+	if outputFlag != "" {
+		if err := writeFileAtomic(outputFlag, []byte(data)); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Print(data)
+	if teeFlag != "" {
+		if err := writeFileAtomic(teeFlag, []byte(data)); err != nil {
+			return fmt.Errorf("failed to write tee file: %w", err)
+		}
+	}
 	return nil
 }
 
 func init() {
-	generateCmd.Flags().StringVarP(&promptFlag, "prompt", "p", "", "Text prompt (required)")
+	generateCmd.Flags().StringVarP(&promptFlag, "prompt", "p", "", "Text prompt")
+	generateCmd.Flags().StringVar(&promptFileFlag, "prompt-file", "", "Read the prompt from a file (prepended to --prompt when both are set)")
+	generateCmd.Flags().BoolVarP(&editFlag, "edit", "e", false, "Compose the prompt in $EDITOR, starting from --prompt/--prompt-file")
+	generateCmd.Flags().StringVar(&stdinPositionFlag, "stdin-position", stdinAfter, "Place piped stdin before or after the prompt flags (before|after)")
+	generateCmd.Flags().StringArrayVar(&contextFiles, "context-file", nil, "File to include as context before the prompt (repeatable)")
+	generateCmd.Flags().IntVar(&maxContextBytes, "max-context-bytes", defaultMaxContextBytes, "Total size budget for --context-file contents; larger files are truncated")
+	generateCmd.Flags().StringVar(&prefixFlag, "prefix", "", "Text placed before the prompt (overrides AI_CLI_PROMPT_PREFIX)")
+	generateCmd.Flags().StringVar(&suffixFlag, "suffix", "", "Text placed after the prompt (overrides AI_CLI_PROMPT_SUFFIX)")
+	generateCmd.Flags().StringVar(&mockResponseFlag, "mock-response", "", "Canned reply for --provider mock (@file reads a file; default echoes the prompt)")
+	generateCmd.Flags().StringVar(&fimSuffixFlag, "fim-suffix", "", "Code after the gap for fill-in-the-middle completion (Mistral; @file reads a file)")
+	generateCmd.Flags().BoolVar(&redactFlag, "redact", false, "Replace API keys, tokens and emails in the prompt with [REDACTED] before sending")
+	generateCmd.Flags().StringVar(&redactFileFlag, "redact-file", "", "Extra redaction regexes, one per line (implies --redact)")
+	generateCmd.Flags().StringVar(&encodingFlag, "encoding", "utf-8", "Text encoding of --prompt-file (e.g. utf-16le, windows-1252)")
 	generateCmd.Flags().StringSliceVarP(&imagesFlag, "images", "i", []string{}, "Image paths")
-	generateCmd.Flags().StringVar(&providerFlag, "provider", "openai", "AI provider (openai|deepseek|mistral)")
+	generateCmd.Flags().StringVar(&profileFlag, "profile", "", "Preset of provider, model and sampling settings from the config file")
+	generateCmd.Flags().StringVar(&systemFlag, "system", "", "System prompt sent before the prompt")
+	generateCmd.Flags().StringVar(&messagesFileFlag, "messages-file", "", "JSON array of {role, content} turns (e.g. few-shot examples) sent before the prompt")
+	generateCmd.Flags().Float64Var(&temperatureFlag, "temperature", 0, "Sampling temperature, 0-2 (default: the provider's)")
+	generateCmd.Flags().IntVar(&maxTokensFlag, "max-tokens", 0, "Maximum tokens in the response (default 1000)")
+	generateCmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Model ID or alias such as fast, smart or vision (default: the provider's default model)")
+	generateCmd.Flags().BoolVar(&showModelFlag, "show-model", false, "Report the model that served the request (stderr, or \"model\" in structured output)")
+	generateCmd.Flags().BoolVar(&logProbsFlag, "logprobs", false, "Return per-token log probabilities (JSON/YAML output; OpenAI only)")
+	generateCmd.Flags().IntVar(&topLogProbs, "top-logprobs", 0, "Also return this many alternatives per token, 0-20 (implies --logprobs)")
+	generateCmd.Flags().BoolVar(&modelFallback, "model-fallback", false, "Retry with the provider's default model if --model is not found")
+	generateCmd.Flags().StringArrayVar(&imageBase64Flag, "image-base64", nil, "Base64-encoded image data, instead of a file path (repeatable)")
+	generateCmd.Flags().StringVar(&imageMimeFlag, "image-mime", "image/png", "MIME type of --image-base64 data (image/png|image/jpeg|image/gif|image/webp)")
+	generateCmd.Flags().BoolVar(&dedupeImages, "dedupe-images", true, "Drop images whose contents duplicate an earlier one (--dedupe-images=false to keep them)")
+	generateCmd.Flags().StringVar(&imageDetail, "image-detail", "", "Vision detail level (low|high|auto); low is much cheaper for simple images")
+	generateCmd.Flags().Float64Var(&confirmCostFlag, "confirm-cost", 0, "Ask before sending a request estimated to cost more than this many USD (0 = never ask)")
+	generateCmd.Flags().BoolVar(&visionCostFlag, "estimate-vision-cost", false, "Print the estimated image token cost to stderr before sending")
+	generateCmd.Flags().StringVar(&providerFlag, "provider", "openai", "AI provider ("+strings.Join(providers.Names(), "|")+"; default from AI_CLI_DEFAULT_PROVIDER)")
+	generateCmd.Flags().StringVar(&baseURLFlag, "base-url", "", "API root of an OpenAI-compatible server for --provider custom (overrides AI_CLI_BASE_URL), lmstudio, llamacpp or vllm")
+	generateCmd.Flags().StringVar(&authHeaderFlag, "auth-header", "", "Header that carries the API key for --provider custom, lmstudio, llamacpp or vllm, instead of Authorization: Bearer")
+	generateCmd.Flags().StringVar(&bedrockAPIFlag, "bedrock-api", providers.BedrockConverse, "Bedrock API: converse, or invoke for the model's native InvokeModel body")
+	generateCmd.Flags().StringSliceVar(&fallbackFlag, "fallback", []string{}, "Providers to try in order if the primary fails with a recoverable error")
 	generateCmd.Flags().StringVarP(&apiKeyFlag, "apikey", "k", "", "API key (overrides environment variable)")
-	generateCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	generateCmd.Flags().StringVar(&apiKeyFileFlag, "apikey-file", "", "Read the API key from a file")
+	generateCmd.Flags().StringVar(&apiKeyCommandFlag, "apikey-command", "", "Run a command (e.g. \"pass show openai\") and use its output as the API key")
+	generateCmd.Flags().StringVar(&orgFlag, "org", "", "OpenAI organization ID (overrides OPENAI_ORG_ID)")
+	generateCmd.Flags().StringVar(&projectFlag, "project", "", "OpenAI project ID (overrides OPENAI_PROJECT_ID)")
+	generateCmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Request timeout in seconds (0 uses the provider default of 30s)")
+	generateCmd.Flags().Int64Var(&maxRespFlag, "max-response-bytes", providers.DefaultMaxResponseBytes, "Maximum size of a provider response body")
+	generateCmd.Flags().StringVar(&caCertFlag, "ca-cert", "", "PEM bundle of extra CA certificates to trust")
+	generateCmd.Flags().BoolVar(&insecureFlag, "insecure-skip-verify", false, "DANGEROUS: disable TLS certificate verification")
+	generateCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (shorthand for --format json)")
+	generateCmd.Flags().BoolVar(&usageFooterFlag, "usage-footer", false, "After the response, print tokens, model and duration as one line on stderr")
+	generateCmd.Flags().StringVar(&outputTemplateFlag, "output-template", "", "Go template for the result, e.g. '{{.Model}}: {{.Content}}' (@file reads it from a file)")
+	generateCmd.Flags().StringVar(&formatFlag, "format", formatText, "Output format (text|json|yaml|xml)")
+	generateCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Write the result to a file instead of stdout")
+	generateCmd.Flags().BoolVar(&stripThinkingFlag, "strip-thinking", false, "Remove reasoning enclosed in --thinking-start/--thinking-end from the response")
+	generateCmd.Flags().StringVar(&thinkingStartFlag, "thinking-start", "<think>", "Opening delimiter removed by --strip-thinking")
+	generateCmd.Flags().StringVar(&thinkingEndFlag, "thinking-end", "</think>", "Closing delimiter removed by --strip-thinking")
+	generateCmd.Flags().BoolVar(&trimFlag, "trim", false, "Strip leading and trailing whitespace from the response (off by default to keep it exact)")
+	generateCmd.Flags().StringVar(&schemaFileFlag, "schema-file", "", "Validate the JSON response against this JSON Schema and print the validated object")
+	generateCmd.Flags().StringVar(&responseSchemaFlag, "response-schema", "", "JSON Schema file sent as a strict structured-output response_format (OpenAI)")
+	generateCmd.Flags().BoolVar(&repairFlag, "repair", false, "With --schema-file, ask the model once to fix a response that fails validation")
+	generateCmd.Flags().StringVar(&extractFlag, "extract", "", "Post-process the response; \"code\" keeps only fenced code blocks")
+	generateCmd.Flags().BoolVar(&extractAllFlag, "all", false, "With --extract code, keep every code block instead of the first")
+	generateCmd.Flags().StringVar(&extractDirFlag, "extract-dir", "", "With --extract code, also write each block to a file named by its language")
+	generateCmd.Flags().StringVar(&teeFlag, "tee", "", "Print the result and also write it to a file")
+	generateCmd.Flags().BoolVar(&watchFlag, "watch", false, "Re-run whenever --prompt-file changes")
+	generateCmd.Flags().StringVar(&userFlag, "user", "", "End-user ID sent for abuse monitoring (overrides AI_CLI_USER)")
+	generateCmd.Flags().StringVar(&extraParamsFlag, "extra-params", "", "JSON object merged into the request payload, e.g. '{\"reasoning_effort\":\"high\"}' (@file reads a file)")
+	generateCmd.Flags().StringArrayVarP(&headerFlags, "header", "H", nil, "Extra request header as key=value (repeatable)")
+	generateCmd.Flags().StringVar(&requestIDFlag, "request-id", "", "Send this ID as the X-Request-ID header")
+	generateCmd.Flags().BoolVar(&compressFlag, "compress", false, "Gzip request bodies over 1 KiB (the endpoint must accept Content-Encoding: gzip)")
+	generateCmd.Flags().BoolVar(&listModelsFlag, "list-models", false, "List the selected provider's models and exit")
+	generateCmd.Flags().BoolVar(&traceFlag, "trace", false, "Log DNS, connect, TLS and connection reuse for each request to stderr")
+	generateCmd.Flags().BoolVar(&dumpCurlFlag, "dump-curl", false, "Print an equivalent curl command for each request to stderr (API key masked)")
+	generateCmd.Flags().BoolVar(&dumpCurlUnsafe, "dump-curl-unsafe", false, "Like --dump-curl but include the real API key")
+	generateCmd.Flags().StringVar(&saveExchangeFlag, "save-exchange", "", "Write each HTTP request (API key masked) and response to this file as JSON")
 	generateCmd.Flags().BoolVar(&debugFlag, "debug", false, "Enable debug logging")
+	generateCmd.Flags().StringVar(&metricsFile, "metrics-file", "", "Write request metrics in Prometheus text format to this file on exit")
 
-	generateCmd.MarkFlagRequired("prompt")
+	generateCmd.MarkFlagsMutuallyExclusive("output", "tee")
 	rootCmd.AddCommand(generateCmd)
 }
 
 func parseInputs() (providers.Inputs, error) {
 	var imageReaders []providers.FileInput
 
-	for _, imgPath := range imagesFlag {
+	imagePaths, err := expandGlobs("--images", imagesFlag)
+	if err != nil {
+		return providers.Inputs{}, &usageError{err: err}
+	}
+
+	for _, imgPath := range imagePaths {
 		file, err := os.Open(imgPath)
 		if err != nil {
 			return providers.Inputs{}, fmt.Errorf("failed to open image %s: %w", imgPath, err)
@@ -122,62 +470,357 @@ func parseInputs() (providers.Inputs, error) {
 		})
 	}
 
+	inline, err := decodeBase64Images(imageBase64Flag, imageMimeFlag)
+	if err != nil {
+		return providers.Inputs{}, &usageError{err: err}
+	}
+	imageReaders = append(imageReaders, inline...)
+
+	if dedupeImages {
+		var removed int
+		if imageReaders, removed = dedupeImageInputs(imageReaders); removed > 0 {
+			fmt.Fprintf(os.Stderr, "removed %d duplicate image(s)\n", removed)
+		}
+	}
+
+	prompt, err := getFinalPrompt()
+	if err != nil {
+		return providers.Inputs{}, err
+	}
+
+	suffix, err := fimSuffix()
+	if err != nil {
+		return providers.Inputs{}, err
+	}
+
+	if strings.TrimSpace(prompt) == "" && len(imageReaders) == 0 && suffix == "" {
+		return providers.Inputs{}, &usageError{err: fmt.Errorf("prompt is empty; provide text via --prompt, --prompt-file or stdin, or pass --images")}
+	}
+
+	if len(contextFiles) > 0 {
+		paths, err := expandGlobs("--context-file", contextFiles)
+		if err != nil {
+			return providers.Inputs{}, &usageError{err: err}
+		}
+		contextBlock, err := buildContext(paths, maxContextBytes)
+		if err != nil {
+			return providers.Inputs{}, err
+		}
+		prompt = strings.TrimRight(contextBlock+"\n\n"+prompt, "\n")
+	}
+
+	// Fill-in-the-middle prompts are code; instructions would corrupt them.
+	if strings.TrimSpace(prompt) != "" && suffix == "" {
+		prompt = wrapPrompt(prompt)
+	}
+
+	messages := append([]providers.Message(nil), seedMessages...)
+	if redactFlag || redactFileFlag != "" {
+		patterns := defaultRedactPatterns
+		if redactFileFlag != "" {
+			extra, err := loadRedactPatterns(redactFileFlag)
+			if err != nil {
+				return providers.Inputs{}, err
+			}
+			patterns = append(append([]*regexp.Regexp{}, patterns...), extra...)
+		}
+
+		var count int
+		prompt, count = redact(prompt, patterns)
+		for i := range messages {
+			var n int
+			messages[i].Content, n = redact(messages[i].Content, patterns)
+			count += n
+		}
+		fmt.Fprintf(os.Stderr, "redacted %d secret(s) from the prompt\n", count)
+	}
+
 	return providers.Inputs{
-		Prompt: promptFlag,
-		Images: imageReaders,
+		Prompt:      prompt,
+		Images:      imageReaders,
+		ImageDetail: imageDetail,
+		Suffix:      suffix,
+		Messages:    messages,
 	}, nil
 }
 
-func getProvider(name, flagKey string) (providers.Provider, error) {
+// wrapPrompt brackets prompt with --prefix and --suffix. The
+// AI_CLI_PROMPT_PREFIX/AI_CLI_PROMPT_SUFFIX variables (e.g. in a project's
+// .env) provide a shared house style that the flags override.
+func wrapPrompt(prompt string) string {
+	parts := []string{
+		firstNonEmpty(prefixFlag, os.Getenv("AI_CLI_PROMPT_PREFIX")),
+		prompt,
+		firstNonEmpty(suffixFlag, os.Getenv("AI_CLI_PROMPT_SUFFIX")),
+	}
+
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+// getFinalPrompt combines the prompt flags, edited first with --edit, with
+// piped stdin, placed after them unless --stdin-position is "before".
+func getFinalPrompt() (string, error) {
+	prompt, err := flagPrompt()
+	if err != nil {
+		return "", err
+	}
+	if editFlag {
+		if prompt, err = editPrompt(prompt); err != nil {
+			return "", err
+		}
+	}
+	stdin, err := readStdinPrompt()
+	if err != nil {
+		return "", err
+	}
+	return combineStdin(prompt, stdin), nil
+}
+
+// flagPrompt combines --prompt-file and --prompt. When both are given the
+// file comes first, followed by a newline and the inline prompt, so a fixed
+// instruction file can be paired with a per-call question.
+func flagPrompt() (string, error) {
+	if promptFileFlag == "" {
+		return promptFlag, nil
+	}
+
+	data, err := os.ReadFile(promptFileFlag)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file %s: %w", promptFileFlag, err)
+	}
+
+	filePrompt, err := decodeText(data, encodingFlag)
+	if err != nil {
+		return "", fmt.Errorf("prompt file %s: %w", promptFileFlag, err)
+	}
+	if promptFlag == "" {
+		return filePrompt, nil
+	}
+	return strings.TrimRight(filePrompt, "\r\n") + "\n" + promptFlag, nil
+}
+
+// insecureWarning prints the --insecure-skip-verify warning once, however
+// many providers (fallbacks, bench, summarize) get built.
+var insecureWarning sync.Once
+
+func getProvider(name, flagKey, model string) (providers.Provider, error) {
+	reg, ok := providers.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
 	key, err := getAPIKey(name, flagKey)
 	if err != nil {
 		return nil, err
 	}
+	model = resolveModelAlias(name, model)
 
 	config := providers.Config{
-		APIKey: key,
-		Debug:  debugFlag,
-	}
-
-	switch name {
-	case "openai":
-		return providers.NewOpenAI(config), nil
-	case "deepseek":
-		return providers.NewDeepSeek(config), nil
-	case "mistral":
-		return providers.NewMistral(config), nil
-	default:
-		return nil, fmt.Errorf("unsupported provider: %s", name)
+		APIKey:           key,
+		Model:            model,
+		Timeout:          timeoutFlag,
+		MaxResponseBytes: maxRespFlag,
+		RequestID:        requestIDFlag,
+		Compress:         compressFlag,
+		LogProbs:         logProbsFlag,
+		TopLogProbs:      topLogProbs,
+		Headers:          extraHeaders,
+		ExtraParams:      extraParams,
+		ResponseSchema:   responseSchema,
+		SystemPrompt:     systemFlag,
+		Temperature:      temperature,
+		MaxTokens:        maxTokensFlag,
+		Trace:            traceFlag,
+		DumpCurl:         dumpCurlFlag || dumpCurlUnsafe,
+		DumpCurlUnsafe:   dumpCurlUnsafe,
+		Exchanges:        exchangeRecorder,
+		User:             firstNonEmpty(userFlag, os.Getenv("AI_CLI_USER")),
+		Debug:            debugFlag,
+
+		CACertFile:         caCertFlag,
+		InsecureSkipVerify: insecureFlag,
 	}
+
+	if insecureFlag {
+		insecureWarning.Do(func() {
+			fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (--insecure-skip-verify). "+
+				"Requests and your API key can be intercepted. Do not use this outside trusted networks.")
+		})
+	}
+
+	if err := configureProvider(reg, &config); err != nil {
+		return nil, err
+	}
+	if config.Model == "" && reg.ModelEnv != "" && reg.DefaultModel == "" {
+		return nil, fmt.Errorf("%s provider requires a model via --model or %s", name, reg.ModelEnv)
+	}
+	return reg.New(config), nil
+}
+
+// configureProvider fills in the settings a provider reads from flags, the
+// environment and the config file, beyond those every provider shares.
+func configureProvider(reg providers.Registration, config *providers.Config) error {
+	if reg.ModelEnv != "" {
+		config.Model = firstNonEmpty(config.Model, os.Getenv(reg.ModelEnv))
+	}
+	if reg.SelfHosted {
+		config.BaseURL = baseURLFlag
+		config.AuthHeader = firstNonEmpty(authHeaderFlag, os.Getenv("AI_CLI_AUTH_HEADER"))
+	}
+	if reg.BaseURLEnv != "" {
+		config.BaseURL = firstNonEmpty(config.BaseURL, os.Getenv(reg.BaseURLEnv))
+		if config.BaseURL == "" && reg.DefaultBaseURL == "" {
+			if reg.SelfHosted {
+				return fmt.Errorf("%s provider requires --base-url or %s", reg.Name, reg.BaseURLEnv)
+			}
+			return fmt.Errorf("%s provider requires %s", reg.Name, reg.BaseURLEnv)
+		}
+	}
+	if setup, ok := providerSetup[reg.Name]; ok {
+		return setup(config)
+	}
+	return nil
+}
+
+// providerSetup holds the settings specific to a single provider.
+var providerSetup = map[string]func(config *providers.Config) error{
+	"openai": func(config *providers.Config) error {
+		config.Organization = firstNonEmpty(orgFlag, os.Getenv("OPENAI_ORG_ID"))
+		config.Project = firstNonEmpty(projectFlag, os.Getenv("OPENAI_PROJECT_ID"))
+		return nil
+	},
+	"azure": func(config *providers.Config) error {
+		config.APIVersion = os.Getenv("AZURE_OPENAI_API_VERSION")
+		return nil
+	},
+	"bedrock": func(config *providers.Config) error {
+		config.AWS = providers.AWSConfig{
+			Region:          firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Profile:         os.Getenv("AWS_PROFILE"),
+			CredentialsFile: os.Getenv("AWS_SHARED_CREDENTIALS_FILE"),
+			ConfigFile:      os.Getenv("AWS_CONFIG_FILE"),
+		}
+		if bedrockAPIFlag != providers.BedrockConverse && bedrockAPIFlag != providers.BedrockInvoke {
+			return &usageError{err: fmt.Errorf("unsupported --bedrock-api value %q (converse|invoke)", bedrockAPIFlag)}
+		}
+		config.BedrockAPI = bedrockAPIFlag
+		return nil
+	},
+	"openrouter": func(config *providers.Config) error {
+		config.Headers = openRouterHeaders(config.Headers)
+		return nil
+	},
+	"vllm": func(config *providers.Config) error {
+		config.VLLM = appConfig.VLLM
+		if err := config.VLLM.Validate(); err != nil {
+			return fmt.Errorf("vllm section of the config file: %w", err)
+		}
+		return nil
+	},
+	"exec": func(config *providers.Config) error {
+		config.Command = os.Getenv("AI_CLI_EXEC_PROVIDER")
+		if config.Command == "" {
+			return fmt.Errorf("exec provider requires AI_CLI_EXEC_PROVIDER to name the command to run")
+		}
+		return nil
+	},
+	"mock": func(config *providers.Config) error {
+		response, err := mockResponse()
+		if err != nil {
+			return err
+		}
+		config.MockResponse = response
+		return nil
+	},
+}
+
+func providerMiddlewares(name string, metrics *providers.Metrics) []providers.ProviderMiddleware {
+	var mws []providers.ProviderMiddleware
+	if metrics != nil {
+		mws = append(mws, providers.WithMetrics(metrics, name))
+	}
+	if debugFlag {
+		mws = append(mws,
+			providers.WithLogging(name, os.Stderr),
+			providers.WithTiming(func(elapsed time.Duration, err error) {
+				fmt.Fprintf(os.Stderr, "[DEBUG] %s: request took %s\n", name, elapsed)
+			}),
+		)
+	}
+	if circuitThreshold > 0 {
+		mws = append(mws, providers.WithCircuitBreaker(name, circuitBreaker(name)))
+	}
+	// Innermost, so metrics and the circuit breaker only see the final outcome.
+	mws = append(mws, providers.WithEmptyResponseRetry())
+	return mws
+}
+
+// writeMetricsFile saves metrics in Prometheus text format. A failed Close
+// is reported too, since that is where a buffered write can fail.
+func writeMetricsFile(path string, metrics *providers.Metrics) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file %s: %w", path, err)
+	}
+
+	if err := metrics.WritePrometheus(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write metrics file %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file %s: %w", path, err)
+	}
+	return nil
 }
 
 func getAPIKey(provider, flagKey string) (string, error) {
 	if flagKey != "" {
 		return flagKey, nil
 	}
-
-	var envVar string
-	switch provider {
-	case "openai":
-		envVar = os.Getenv("OPENAI_API_KEY")
-	case "deepseek":
-		envVar = os.Getenv("DEEPSEEK_API_KEY")
-	case "mistral":
-		envVar = os.Getenv("MISTRAL_API_KEY")
-	default:
+	reg, ok := providers.Lookup(provider)
+	if !ok {
 		return "", fmt.Errorf("unsupported provider: %s", provider)
 	}
+	if reg.APIKeyEnv == "" {
+		return "", nil
+	}
 
-	if envVar == "" {
-		return "", fmt.Errorf("API key required for %s. Set via --apikey or environment variable", provider)
+	key := os.Getenv(reg.APIKeyEnv)
+	if key == "" && !reg.SelfHosted {
+		return "", fmt.Errorf("API key required for %s. Set via --apikey, --apikey-command, --apikey-file or environment variable", provider)
 	}
+	return key, nil
+}
 
-	return envVar, nil
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 func validateCapabilities(p providers.Provider, inputs providers.Inputs) error {
 	if len(inputs.Images) > 0 && !p.Supports(providers.FeatureVision) {
 		return fmt.Errorf("selected provider doesn't support image analysis")
 	}
+	if responseSchemaFlag != "" && !p.Supports(providers.FeatureStructuredOutput) {
+		return fmt.Errorf("selected provider doesn't support --response-schema structured outputs")
+	}
+	if (logProbsFlag || topLogProbs > 0) && !p.Supports(providers.FeatureLogProbs) {
+		return fmt.Errorf("selected provider doesn't support --logprobs")
+	}
+	if inputs.Suffix != "" && !p.Supports(providers.FeatureFIM) {
+		return fmt.Errorf("selected provider doesn't support fill-in-the-middle (--fim-suffix)")
+	}
 	return nil
 }
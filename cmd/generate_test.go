@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"ai-cli/internal/providers"
+)
+
+func TestInsecureWarningPrintedOnce(t *testing.T) {
+	insecureFlag = true
+	defer func() { insecureFlag = false }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	for range 3 {
+		if _, err := getProvider("mock", "", ""); err != nil {
+			os.Stderr = stderr
+			t.Fatalf("getProvider: %v", err)
+		}
+	}
+	os.Stderr = stderr
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	if n := strings.Count(string(out), "TLS certificate verification is disabled"); n != 1 {
+		t.Errorf("warning printed %d times, want 1:\n%s", n, out)
+	}
+}
+
+func TestWriteMetricsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/metrics.prom"
+	if err := writeMetricsFile(path, providers.NewMetrics()); err != nil {
+		t.Fatalf("writeMetricsFile: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("metrics file not written: %v", err)
+	}
+
+	if err := writeMetricsFile(dir+"/missing/metrics.prom", providers.NewMetrics()); err == nil {
+		t.Error("writing into a missing directory succeeded")
+	}
+	if _, err := os.Stat("/dev/full"); err == nil {
+		if err := writeMetricsFile("/dev/full", providers.NewMetrics()); err == nil {
+			t.Error("writing to /dev/full succeeded")
+		}
+	}
+}
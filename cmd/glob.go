@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// expandGlobs expands wildcard patterns (including **) itself, since not
+// every shell does, and Windows shells never do. Plain paths are kept as-is
+// so a missing file is reported when it is opened. Results are deduplicated
+// in first-seen order; a pattern that matches nothing is an error.
+func expandGlobs(flag string, patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		key := filepath.Clean(path)
+		if !seen[key] {
+			seen[key] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[{") {
+			add(pattern)
+			continue
+		}
+
+		matches, err := doublestar.FilepathGlob(pattern, doublestar.WithFilesOnly())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", flag, pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s pattern %q matched no files", flag, pattern)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+	return paths, nil
+}
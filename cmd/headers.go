@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	headerFlags  []string
+	extraHeaders map[string]string
+)
+
+// reservedHeaders are set by the providers themselves; overriding them is
+// allowed (a gateway may want its own Authorization) but usually a mistake.
+var reservedHeaders = []string{"Authorization", "Content-Type"}
+
+// parseHeaders turns repeated --header key=value flags into a header map,
+// warning on stderr when a reserved header is overridden.
+func parseHeaders(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --header %q (expected key=value)", v)
+		}
+		key = http.CanonicalHeaderKey(key)
+		for _, reserved := range reservedHeaders {
+			if key == reserved {
+				fmt.Fprintf(os.Stderr, "Warning: --header overrides the %s header set by the provider\n", key)
+			}
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// openRouterHeaders adds OpenRouter's app attribution headers from
+// OPENROUTER_SITE_URL and OPENROUTER_APP_NAME to headers, without replacing
+// ones given with --header.
+func openRouterHeaders(headers map[string]string) map[string]string {
+	merged := make(map[string]string, len(headers)+2)
+	for _, h := range []struct{ key, env string }{
+		{"Http-Referer", "OPENROUTER_SITE_URL"},
+		{"X-Title", "OPENROUTER_APP_NAME"},
+	} {
+		if value := os.Getenv(h.env); value != "" {
+			merged[h.key] = value
+		}
+	}
+	for key, value := range headers {
+		merged[key] = value
+	}
+	return merged
+}
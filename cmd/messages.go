@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-cli/internal/providers"
+)
+
+var (
+	messagesFileFlag string
+
+	// seedMessages are the turns from --messages-file, sent ahead of the
+	// prompt.
+	seedMessages []providers.Message
+)
+
+// loadMessages reads a JSON array of {role, content} turns, e.g. few-shot
+// examples, and checks that the prompt can follow them as the next user
+// turn: system messages come first, then user and assistant alternate,
+// starting with user and ending with assistant.
+func loadMessages(path string) ([]providers.Message, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --messages-file: %w", err)
+	}
+
+	var messages []providers.Message
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&messages); err != nil {
+		return nil, fmt.Errorf("invalid --messages-file %s: expected a JSON array of {role, content} objects: %w", path, err)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("--messages-file %s contains no messages", path)
+	}
+
+	expect := "user"
+	for i, msg := range messages {
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("--messages-file %s: message %d: %s", path, i+1, fmt.Sprintf(format, args...))
+		}
+		if strings.TrimSpace(msg.Content) == "" {
+			return nil, fail("content is empty")
+		}
+		switch msg.Role {
+		case "system":
+			if i > 0 && messages[i-1].Role != "system" {
+				return nil, fail("system messages must come before the conversation")
+			}
+		case "user", "assistant":
+			if msg.Role != expect {
+				return nil, fail("expected a %s turn, got %s; user and assistant turns must alternate, starting with user", expect, msg.Role)
+			}
+			if expect == "user" {
+				expect = "assistant"
+			} else {
+				expect = "user"
+			}
+		default:
+			return nil, fail("unsupported role %q (system|user|assistant)", msg.Role)
+		}
+	}
+	if expect == "assistant" {
+		return nil, fmt.Errorf("--messages-file %s must end with an assistant turn; the prompt is sent as the next user turn", path)
+	}
+	return messages, nil
+}
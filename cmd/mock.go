@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+var mockResponseFlag string
+
+// mockResponse returns the canned reply for --provider mock, read from a
+// file when --mock-response starts with "@".
+func mockResponse() (string, error) {
+	path, ok := strings.CutPrefix(mockResponseFlag, "@")
+	if !ok {
+		return mockResponseFlag, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --mock-response file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
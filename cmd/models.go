@@ -1,33 +1,48 @@
 package cmd
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"ai-cli/internal/providers"
 
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
 var (
 	modelsProvider []string
 	modelsJson     bool
+	modelsFormat   string
+	modelsAll      bool
+	modelsChatOnly bool
+
+	modelsNoTruncate bool
 )
 
 var modelsCmd = &cobra.Command{
 	Use:   "models",
 	Short: "List available models for supported providers",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-		_ = godotenv.Load()
+		ctx := cmd.Context()
+		_, _ = loadDotenv()
+
+		format, err := resolveFormat(modelsFormat, modelsJson)
+		if err != nil {
+			return err
+		}
+		if extraHeaders, err = parseHeaders(headerFlags); err != nil {
+			return &usageError{err: err}
+		}
+		if modelsAll && modelsChatOnly && cmd.Flags().Changed("chat-only") {
+			return &usageError{err: fmt.Errorf("--chat-only and --include-deprecated can't be used together")}
+		}
 
 		if len(modelsProvider) == 0 {
-			modelsProvider = []string{"openai", "deepseek", "mistral"}
+			modelsProvider = providers.Defaults()
 		}
 
 		providerModels := make(map[string][]providers.Model)
@@ -53,6 +68,7 @@ var modelsCmd = &cobra.Command{
 				continue
 			}
 
+			sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
 			providerModels[provider] = models
 		}
 
@@ -62,12 +78,20 @@ var modelsCmd = &cobra.Command{
 			}
 		}
 
-		if modelsJson {
-			jsonData, _ := json.MarshalIndent(providerModels, "", "  ")
-			fmt.Println(string(jsonData))
+		// JSON and YAML encoders already emit map keys sorted.
+		if format != formatText {
+			data, _ := marshalStructured(format, providerModels)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
 		} else {
-			for provider, models := range providerModels {
-				printProviderTable(provider, models)
+			// Map keys come back in random order; sort for stable output.
+			names := make([]string, 0, len(providerModels))
+			for provider := range providerModels {
+				names = append(names, provider)
+			}
+			sort.Strings(names)
+
+			for _, provider := range names {
+				printProviderTable(provider, providerModels[provider])
 				fmt.Println()
 			}
 		}
@@ -82,61 +106,85 @@ func printProviderTable(provider string, models []providers.Model) {
 		return
 	}
 
-	fmt.Println("┌──────────────────────┬──────────────────────┬──────────────┬─────────────┐")
-	fmt.Println("│ Model ID             │ Description          │ Context Size │ Vision      │")
-	fmt.Println("├──────────────────────┼──────────────────────┼──────────────┼─────────────┤")
+	idWidth, descWidth := 20, 20
+	if modelsNoTruncate {
+		for _, m := range models {
+			idWidth = max(idWidth, utf8.RuneCountInString(m.ID))
+			descWidth = max(descWidth, utf8.RuneCountInString(m.Description))
+		}
+	}
+	cell := func(s string, width int) string {
+		if modelsNoTruncate {
+			return s
+		}
+		return truncate(s, width)
+	}
+	rule := func(left, mid, right string) string {
+		return left + strings.Repeat("─", idWidth+2) + mid + strings.Repeat("─", descWidth+2) + mid +
+			"──────────────" + mid + "─────────────" + right
+	}
+
+	fmt.Println(rule("┌", "┬", "┐"))
+	fmt.Printf("│ %-*s │ %-*s │ Context Size │ Vision      │\n", idWidth, "Model ID", descWidth, "Description")
+	fmt.Println(rule("├", "┼", "┤"))
 	for _, m := range models {
-		fmt.Printf("│ %-20s │ %-20s │ %-12d │ %-11v │\n",
-			truncate(m.ID, 20),
-			truncate(m.Description, 20),
+		fmt.Printf("│ %-*s │ %-*s │ %-12d │ %-11v │\n",
+			idWidth, cell(m.ID, idWidth),
+			descWidth, cell(m.Description, descWidth),
 			m.ContextWindow,
 			m.SupportsVision)
 	}
-	fmt.Println("└──────────────────────┴──────────────────────┴──────────────┴─────────────┘")
+	fmt.Println(rule("└", "┴", "┘"))
 }
 
 func init() {
-	modelsCmd.Flags().StringSliceVar(&modelsProvider, "provider", []string{}, "Comma-separated list of providers (openai,deepseek,mistral)")
-	modelsCmd.Flags().BoolVar(&modelsJson, "json", false, "Output in JSON format")
+	modelsCmd.Flags().StringSliceVar(&modelsProvider, "provider", []string{}, "Comma-separated list of providers ("+strings.Join(modelListerNames(), ",")+")")
+	modelsCmd.Flags().BoolVar(&modelsChatOnly, "chat-only", true, "List only current chat models; --chat-only=false lists everything")
+	modelsCmd.Flags().BoolVar(&modelsAll, "include-deprecated", false, "List every model, including embeddings, audio, image and dated snapshots")
+	modelsCmd.Flags().StringArrayVarP(&headerFlags, "header", "H", nil, "Extra request header as key=value (repeatable)")
+	modelsCmd.Flags().BoolVar(&modelsNoTruncate, "no-truncate", false, "Print full model IDs and descriptions, widening the table to fit")
+	modelsCmd.Flags().BoolVar(&modelsNoTruncate, "wide", false, "Alias for --no-truncate")
+	modelsCmd.Flags().BoolVar(&modelsJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	modelsCmd.Flags().StringVar(&modelsFormat, "format", formatText, "Output format (text|json|yaml|xml)")
 	rootCmd.AddCommand(modelsCmd)
 }
 
 func getAPIKeyForProvider(provider string) (string, error) {
-	switch provider {
-	case "openai":
-		key := os.Getenv("OPENAI_API_KEY")
-		if key == "" {
-			return "", fmt.Errorf("OPENAI_API_KEY not found in environment")
-		}
-		return key, nil
-	case "deepseek":
-		key := os.Getenv("DEEPSEEK_API_KEY")
-		if key == "" {
-			return "", fmt.Errorf("DEEPSEEK_API_KEY not found in environment")
-		}
-		return key, nil
-	case "mistral":
-		key := os.Getenv("MISTRAL_API_KEY")
-		if key == "" {
-			return "", fmt.Errorf("MISTRAL_API_KEY not found in environment")
-		}
-		return key, nil
-	default:
+	reg, ok := providers.Lookup(provider)
+	if !ok || !reg.ListsModels() {
 		return "", fmt.Errorf("unsupported provider")
 	}
+	if reg.APIKeyEnv == "" {
+		return "", nil
+	}
+	key := os.Getenv(reg.APIKeyEnv)
+	if key == "" && !reg.SelfHosted {
+		return "", fmt.Errorf("%s not found in environment", reg.APIKeyEnv)
+	}
+	return key, nil
+}
+
+// modelListerNames lists the providers that can list their models.
+func modelListerNames() []string {
+	var names []string
+	for _, name := range providers.Names() {
+		if reg, _ := providers.Lookup(name); reg.ListsModels() {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 func getModelLister(provider string, apiKey string) (providers.ModelLister, error) {
-	switch provider {
-	case "openai":
-		return providers.NewOpenAI(providers.Config{APIKey: apiKey}), nil
-	case "deepseek":
-		return providers.NewDeepSeek(providers.Config{APIKey: apiKey}), nil
-	case "mistral":
-		return providers.NewMistral(providers.Config{APIKey: apiKey}), nil
-	default:
+	reg, ok := providers.Lookup(provider)
+	if !ok {
 		return nil, fmt.Errorf("unsupported provider")
 	}
+	config := providers.Config{APIKey: apiKey, Headers: extraHeaders, IncludeAllModels: modelsAll || !modelsChatOnly}
+	if err := configureProvider(reg, &config); err != nil {
+		return nil, err
+	}
+	return providers.NewModelLister(provider, config)
 }
 
 func getProviderName(modelID string) string {
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModelsChatOnlyConflict(t *testing.T) {
+	defer func() {
+		modelsAll, modelsChatOnly = false, true
+		modelsCmd.Flags().Lookup("chat-only").Changed = false
+		modelsCmd.Flags().Lookup("include-deprecated").Changed = false
+	}()
+	if err := modelsCmd.ParseFlags([]string{"--chat-only", "--include-deprecated"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := modelsCmd.RunE(modelsCmd, nil)
+	var usage *usageError
+	if !errors.As(err, &usage) {
+		t.Fatalf("err = %v, want a usage error", err)
+	}
+}
+
+func TestModelsChatOnlyDefault(t *testing.T) {
+	if !modelsChatOnly || modelsAll {
+		t.Errorf("chat-only = %v, include-deprecated = %v; want the list filtered by default", modelsChatOnly, modelsAll)
+	}
+}
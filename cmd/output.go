@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"ai-cli/internal/providers"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatXML  = "xml"
+)
+
+var prettyFlag bool
+
+// resolveFormat combines --format with the legacy --json shorthand.
+func resolveFormat(format string, jsonFlag bool) (string, error) {
+	if jsonFlag {
+		return formatJSON, nil
+	}
+	switch format {
+	case formatText, formatJSON, formatYAML, formatXML:
+		return format, nil
+	default:
+		return "", &usageError{err: fmt.Errorf("unsupported output format %q (text|json|yaml|xml)", format)}
+	}
+}
+
+// marshalStructured encodes v for the machine-readable formats.
+func marshalStructured(format string, v any) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(v)
+	case formatXML:
+		return marshalXML(v)
+	default:
+		return marshalJSON(v)
+	}
+}
+
+// xmlProviderModels is the XML shape of a provider's model list, since
+// encoding/xml can't marshal maps.
+type xmlProviderModels struct {
+	Name   string            `xml:"name,attr"`
+	Models []providers.Model `xml:"model"`
+}
+
+// marshalXML encodes v as an XML document honouring --pretty. A single value
+// becomes <result>, a slice <results> of <result> elements, and a
+// provider->models map <models> of <provider name="..."> elements.
+func marshalXML(v any) ([]byte, error) {
+	root := "result"
+	switch value := v.(type) {
+	case map[string][]providers.Model:
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		list := make([]xmlProviderModels, 0, len(names))
+		for _, name := range names {
+			list = append(list, xmlProviderModels{Name: name, Models: value[name]})
+		}
+		v = struct {
+			Providers []xmlProviderModels `xml:"provider"`
+		}{list}
+		root = "models"
+	default:
+		if reflect.ValueOf(v).Kind() == reflect.Slice {
+			v = struct {
+				Items any `xml:"result"`
+			}{v}
+			root = "results"
+		}
+	}
+
+	var data []byte
+	var err error
+	if prettyFlag {
+		data, err = xml.MarshalIndent(wrapXML{root, v}, "", "  ")
+	} else {
+		data, err = xml.Marshal(wrapXML{root, v})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// wrapXML names the document's root element.
+type wrapXML struct {
+	name  string
+	value any
+}
+
+func (w wrapXML) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(w.value, xml.StartElement{Name: xml.Name{Local: w.name}})
+}
+
+// marshalJSON encodes v honouring --pretty so every command formats JSON the
+// same way.
+func marshalJSON(v any) ([]byte, error) {
+	if prettyFlag {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
+
+func stdoutIsTerminal() bool {
+	return isTerminal(os.Stdout)
+}
+
+func stdinIsTerminal() bool {
+	return isTerminal(os.Stdin)
+}
+
+// stdinIsPiped reports whether stdin is a pipe or a redirected file.
+// Sockets and devices inherited from a supervisor or CI runner are neither,
+// so optional stdin input never blocks waiting on them.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeFileAtomic writes data to a ".partial" file next to path and renames
+// it into place only once everything has been written, so readers see either
+// the old file or the complete new one.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.partial")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
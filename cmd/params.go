@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	extraParamsFlag string
+	extraParams     map[string]any
+)
+
+// parseExtraParams decodes --extra-params, a JSON object (or @file holding
+// one) merged into every request payload. Numbers are kept as written so
+// large integers survive the round trip.
+func parseExtraParams(value string) (map[string]any, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data := []byte(value)
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read --extra-params file: %w", err)
+		}
+	}
+
+	var params map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil || params == nil {
+		return nil, fmt.Errorf("--extra-params must be a JSON object, e.g. '{\"reasoning_effort\":\"high\"}'")
+	}
+	return params, nil
+}
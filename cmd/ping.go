@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-cli/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pingProviders []string
+	pingTimeout   time.Duration
+	pingJson      bool
+	pingFormat    string
+)
+
+type PingResult struct {
+	Provider  string `json:"provider" yaml:"provider" xml:"provider"`
+	OK        bool   `json:"ok" yaml:"ok" xml:"ok"`
+	Reachable bool   `json:"reachable" yaml:"reachable" xml:"reachable"`
+	AuthOK    bool   `json:"auth_ok" yaml:"auth_ok" xml:"auth_ok"`
+	LatencyMs int64  `json:"latency_ms" yaml:"latency_ms" xml:"latency_ms"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+}
+
+var pingCmd = &cobra.Command{
+	Use:     "ping",
+	Aliases: []string{"health"},
+	Short:   "Check that provider endpoints are reachable and API keys work",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		_, _ = loadDotenv()
+
+		format, err := resolveFormat(pingFormat, pingJson)
+		if err != nil {
+			return err
+		}
+
+		names := pingProviders
+		if len(names) == 0 {
+			names = providers.Defaults()
+		}
+
+		results := make([]PingResult, len(names))
+		errs := make([]error, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				results[i], errs[i] = pingProvider(cmd.Context(), strings.ToLower(name))
+			}(i, name)
+		}
+		wg.Wait()
+
+		if format != formatText {
+			data, _ := marshalStructured(format, results)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
+		} else {
+			printPingTable(results)
+		}
+
+		// The table already shows every failure; the first one sets the exit code.
+		for i, err := range errs {
+			if err != nil {
+				return &silentError{err: fmt.Errorf("%s: %w", results[i].Provider, err)}
+			}
+		}
+		return nil
+	},
+}
+
+func pingProvider(ctx context.Context, name string) (PingResult, error) {
+	result := PingResult{Provider: name}
+
+	key, err := getAPIKeyForProvider(name)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	lister, err := getModelLister(name, key)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = lister.ListModels(ctx)
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	if err == nil {
+		result.OK, result.Reachable, result.AuthOK = true, true, true
+		return result, nil
+	}
+
+	result.Error = err.Error()
+	var apiErr *providers.APIError
+	if errors.As(err, &apiErr) {
+		// The endpoint answered, so it's reachable even if it refused us.
+		result.Reachable = true
+		result.AuthOK = !apiErr.IsAuth()
+	}
+	return result, err
+}
+
+func printPingTable(results []PingResult) {
+	fmt.Println("┌──────────────┬───────────┬─────────┬────────────┬──────────────────────────────┐")
+	fmt.Println("│ Provider     │ Reachable │ Auth    │ Latency    │ Error                        │")
+	fmt.Println("├──────────────┼───────────┼─────────┼────────────┼──────────────────────────────┤")
+	for _, r := range results {
+		fmt.Printf("│ %-12s │ %-9s │ %-7s │ %-10s │ %-28s │\n",
+			truncate(r.Provider, 12),
+			checkMark(r.Reachable),
+			checkMark(r.AuthOK),
+			fmt.Sprintf("%dms", r.LatencyMs),
+			truncate(r.Error, 28))
+	}
+	fmt.Println("└──────────────┴───────────┴─────────┴────────────┴──────────────────────────────┘")
+}
+
+func checkMark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
+func init() {
+	pingCmd.Flags().StringSliceVar(&pingProviders, "provider", []string{}, "Comma-separated list of providers to check (default "+strings.Join(providers.Defaults(), ",")+")")
+	pingCmd.Flags().DurationVar(&pingTimeout, "timeout", 5*time.Second, "Timeout per provider")
+	pingCmd.Flags().BoolVar(&pingJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	pingCmd.Flags().StringVar(&pingFormat, "format", formatText, "Output format (text|json|yaml|xml)")
+	rootCmd.AddCommand(pingCmd)
+}
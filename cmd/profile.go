@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// profile is a named preset from the config file's profiles section.
+// Each value fills in the flag of the same name unless that flag was given.
+type profile struct {
+	Provider    string   `yaml:"provider"`
+	Model       string   `yaml:"model"`
+	Temperature *float64 `yaml:"temperature"`
+	System      string   `yaml:"system"`
+	MaxTokens   int      `yaml:"max_tokens"`
+	BaseURL     string   `yaml:"base_url"`
+}
+
+var profileFlag string
+
+// applyProfile copies the --profile preset into the flags the user didn't
+// set, so explicit flags always win.
+func applyProfile(cmd *cobra.Command) error {
+	if profileFlag == "" {
+		return nil
+	}
+	p, ok := appConfig.Profiles[profileFlag]
+	if !ok {
+		names := make([]string, 0, len(appConfig.Profiles))
+		for name := range appConfig.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: the config file %s defines no profiles", profileFlag, configPath())
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", profileFlag, strings.Join(names, ", "))
+	}
+
+	values := map[string]string{
+		"provider": p.Provider,
+		"model":    p.Model,
+		"system":   p.System,
+		"base-url": p.BaseURL,
+	}
+	if p.Temperature != nil {
+		values["temperature"] = strconv.FormatFloat(*p.Temperature, 'g', -1, 64)
+	}
+	if p.MaxTokens != 0 {
+		values["max-tokens"] = strconv.Itoa(p.MaxTokens)
+	}
+
+	for name, value := range values {
+		if value == "" || cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("profile %q: invalid %s: %w", profileFlag, name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	systemFlag      string
+	temperatureFlag float64
+	maxTokensFlag   int
+
+	// temperature is --temperature when it was given (directly or by a
+	// profile); nil leaves the provider's default.
+	temperature *float64
+)
+
+// validateSampling checks --temperature and --max-tokens and records
+// whether a temperature should be sent at all.
+func validateSampling(cmd *cobra.Command) error {
+	if maxTokensFlag < 0 {
+		return fmt.Errorf("--max-tokens must not be negative")
+	}
+	if !cmd.Flags().Changed("temperature") {
+		return nil
+	}
+	if temperatureFlag < 0 || temperatureFlag > 2 {
+		return fmt.Errorf("--temperature must be between 0 and 2")
+	}
+	temperature = &temperatureFlag
+	return nil
+}
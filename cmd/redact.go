@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+// defaultRedactPatterns catch the secrets most often pasted into prompts by
+// accident.
+var defaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`(?i)aws_?secret_?access_?key["'\s:=]+[A-Za-z0-9/+=]{40}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+}
+
+// redact replaces every match of patterns in text and returns the number of
+// replacements made.
+func redact(text string, patterns []*regexp.Regexp) (string, int) {
+	count := 0
+	for _, re := range patterns {
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return redactedPlaceholder
+		})
+	}
+	return text, count
+}
+
+// loadRedactPatterns reads one regular expression per line from path.
+// Blank lines and lines starting with # are ignored.
+func loadRedactPatterns(path string) ([]*regexp.Regexp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open redact file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		re, err := regexp.Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern: %w", path, line, err)
+		}
+		patterns = append(patterns, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read redact file %s: %w", path, err)
+	}
+	return patterns, nil
+}
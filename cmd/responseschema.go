@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ai-cli/internal/providers"
+)
+
+var (
+	responseSchemaFlag string
+	responseSchema     *providers.ResponseSchema
+)
+
+// invalidSchemaNameChars are the characters OpenAI doesn't allow in a
+// structured-output schema name.
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// loadResponseSchema reads --response-schema, a JSON Schema object, and
+// names it after the file, e.g. invoice.schema.json → invoice_schema.
+func loadResponseSchema(path string) (*providers.ResponseSchema, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --response-schema: %w", err)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("--response-schema %s must contain a JSON object", path)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = invalidSchemaNameChars.ReplaceAllString(name, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	if name == "" {
+		name = "response"
+	}
+	return &providers.ResponseSchema{Name: name, Schema: json.RawMessage(data)}, nil
+}
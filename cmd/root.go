@@ -1,24 +1,113 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"ai-cli/internal/providers"
+
+	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
 
+var (
+	maxConcurrencyFlag int
+	retryBudgetFlag    int
+	circuitThreshold   int
+	circuitWindow      time.Duration
+	circuitCooldown    time.Duration
+	noDotenvFlag       bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "ai-cli",
 	Short: "AI-powered CLI for multimodal generation",
 	Long: `Interactive CLI supporting text and image generation through multiple AI providers.
 
+Exit codes:
+  0 success, 1 generic failure, 2 usage error, 3 authentication failure,
+  4 rate limited, 5 network error or timeout, 130 cancelled
+
 Examples:
   $ ai-cli generate -p "Explain quantum computing"
   $ ai-cli generate -p "Describe this image" -i photo.jpg --json
   $ ai-cli generate -p "Explain diagram" -i diagram.png --provider openai`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Cobra checks required flags after the pre-run hooks; doing it here
+		// lets us tag the failure as a usage error.
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return &usageError{err: err}
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return &usageError{err: err}
+		}
+		if maxConcurrencyFlag < 0 {
+			return &usageError{err: fmt.Errorf("--max-concurrency-global must not be negative")}
+		}
+		providers.SetMaxConcurrentRequests(maxConcurrencyFlag)
+		providers.SetRetryBudget(retryBudgetFlag)
+
+		var err error
+		if appConfig, err = loadConfig(); err != nil {
+			// A broken config file isn't a flag mistake; skip the usage dump.
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
+	},
+}
+
+// loadDotenv reads .env from the working directory unless --no-dotenv or
+// AI_CLI_NO_DOTENV is set, so CI can rely on its real environment only.
+// skipped is true when loading was disabled.
+func loadDotenv() (skipped bool, err error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv("AI_CLI_NO_DOTENV")); noDotenvFlag || disabled {
+		return true, nil
+	}
+	return false, godotenv.Load()
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	cancelled := ctx.Err() != nil
+	stop()
+
+	if cancelled {
+		fmt.Fprintln(os.Stderr, "cancelled")
+		os.Exit(exitCancelled)
+	}
+	if err != nil {
+		var silentErr *silentError
+		if !errors.As(err, &silentErr) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			if hint := errorHint(err); hint != "" {
+				fmt.Fprintln(os.Stderr, "Hint:", hint)
+			}
+		}
+		os.Exit(exitCode(err))
 	}
 }
+
+func init() {
+	// Execute reports errors itself so failures already rendered as JSON
+	// are not printed twice.
+	rootCmd.SilenceErrors = true
+
+	rootCmd.PersistentFlags().BoolVar(&prettyFlag, "pretty", stdoutIsTerminal(), "Indent JSON output (defaults to on for terminals, off when piped)")
+	rootCmd.PersistentFlags().IntVar(&maxConcurrencyFlag, "max-concurrency-global", 0, "Maximum HTTP requests in flight across all providers (0 = unlimited)")
+	rootCmd.PersistentFlags().IntVar(&retryBudgetFlag, "retry-budget", -1, "Total retries allowed across all requests in this run (-1 = unlimited)")
+	rootCmd.PersistentFlags().IntVar(&circuitThreshold, "circuit-threshold", 0, "Consecutive provider failures that open its circuit breaker (0 = disabled)")
+	rootCmd.PersistentFlags().DurationVar(&circuitWindow, "circuit-window", time.Minute, "Window in which --circuit-threshold failures must occur")
+	rootCmd.PersistentFlags().DurationVar(&circuitCooldown, "circuit-cooldown", 30*time.Second, "How long an open circuit fails fast before probing again")
+	rootCmd.PersistentFlags().BoolVar(&noDotenvFlag, "no-dotenv", false, "Don't load .env from the working directory (or set AI_CLI_NO_DOTENV=1)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+}
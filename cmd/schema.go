@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-cli/internal/providers"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	schemaFileFlag string
+	repairFlag     bool
+)
+
+// enforceSchema validates a JSON response against the schema at path
+// (--schema-file or --response-schema) and returns it re-encoded. With
+// --repair, one follow-up request shows the model its answer and the
+// validation errors and asks for a corrected version.
+func enforceSchema(ctx context.Context, path string, inputs providers.Inputs, content string, metrics *providers.Metrics) (string, error) {
+	schema, err := jsonschema.Compile(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+
+	doc, verr := validateJSON(schema, content)
+	if verr == nil {
+		return doc, nil
+	}
+	if !repairFlag {
+		return "", fmt.Errorf("response does not match %s: %w", path, verr)
+	}
+
+	fmt.Fprintf(os.Stderr, "response does not match %s; requesting a repair\n", path)
+	repair := inputs
+	repair.Prompt = repairPrompt(inputs.Prompt, content, verr)
+
+	candidates := append([]string{providerFlag}, fallbackFlag...)
+	result, _, _, err := generateWithFallback(ctx, candidates, repair, metrics)
+	if err != nil {
+		return "", fmt.Errorf("repair request failed: %w", err)
+	}
+
+	doc, verr = validateJSON(schema, result.Content)
+	if verr != nil {
+		return "", fmt.Errorf("repaired response still does not match %s: %w", path, verr)
+	}
+	return doc, nil
+}
+
+// validateJSON parses content (or, failing that, its first fenced code
+// block, since models like to wrap JSON in ```json) and validates it.
+func validateJSON(schema *jsonschema.Schema, content string) (string, error) {
+	v, err := decodeJSON(content)
+	if err != nil {
+		blocks := extractCodeBlocks(content)
+		if len(blocks) == 0 {
+			return "", fmt.Errorf("response is not valid JSON: %w", err)
+		}
+		if v, err = decodeJSON(blocks[0].Code); err != nil {
+			return "", fmt.Errorf("response is not valid JSON: %w", err)
+		}
+	}
+
+	if err := schema.Validate(v); err != nil {
+		return "", errors.New(schemaErrorText(err))
+	}
+
+	data, err := marshalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeJSON(text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return v, nil
+}
+
+// schemaErrorText lists every failing location instead of only the first.
+func schemaErrorText(err error) string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err.Error()
+	}
+
+	var lines []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", location, e.Message))
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(ve)
+	return strings.Join(lines, "; ")
+}
+
+func repairPrompt(prompt, response string, verr error) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n\n", prompt)
+	fmt.Fprintf(&b, "Your previous response was:\n%s\n\n", response)
+	fmt.Fprintf(&b, "It failed JSON Schema validation: %v\n\n", verr)
+	b.WriteString("Reply with only the corrected JSON, no explanation or code fences.")
+	return b.String()
+}
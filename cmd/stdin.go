@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	stdinAfter  = "after"
+	stdinBefore = "before"
+)
+
+var stdinPositionFlag string
+
+var (
+	stdinOnce sync.Once
+	stdinText string
+	stdinErr  error
+)
+
+// readStdinPrompt returns piped stdin, read once so --watch reruns reuse it.
+// It is empty unless stdin is a pipe or redirected file.
+func readStdinPrompt() (string, error) {
+	stdinOnce.Do(func() {
+		if !stdinIsPiped() {
+			return
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			stdinErr = fmt.Errorf("failed to read stdin: %w", err)
+			return
+		}
+		stdinText = strings.TrimRight(string(data), "\r\n")
+	})
+	return stdinText, stdinErr
+}
+
+// combineStdin joins the flag prompt and piped stdin. By default the flags
+// are the instruction and stdin the document it applies to, placed after
+// it; --stdin-position before puts the document first.
+func combineStdin(prompt, stdin string) string {
+	switch {
+	case stdin == "":
+		return prompt
+	case prompt == "":
+		return stdin
+	case stdinPositionFlag == stdinBefore:
+		return stdin + "\n" + prompt
+	default:
+		return strings.TrimRight(prompt, "\r\n") + "\n" + stdin
+	}
+}
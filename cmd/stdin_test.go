@@ -0,0 +1,32 @@
+package cmd
+
+import "testing"
+
+func TestCombineStdin(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   string
+		stdin    string
+		position string
+		want     string
+	}{
+		{"prompt only", "Summarize", "", stdinAfter, "Summarize"},
+		{"stdin only", "", "some text", stdinAfter, "some text"},
+		{"stdin only, before", "", "some text", stdinBefore, "some text"},
+		{"neither", "", "", stdinAfter, ""},
+		{"stdin after prompt", "Summarize", "some text", stdinAfter, "Summarize\nsome text"},
+		{"trailing newlines trimmed from prompt", "Summarize\r\n\n", "some text", stdinAfter, "Summarize\nsome text"},
+		{"stdin before prompt", "Summarize", "some text", stdinBefore, "some text\nSummarize"},
+		{"multi-line stdin", "Fix:", "line 1\nline 2", stdinAfter, "Fix:\nline 1\nline 2"},
+	}
+
+	defer func(old string) { stdinPositionFlag = old }(stdinPositionFlag)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stdinPositionFlag = tc.position
+			if got := combineStdin(tc.prompt, tc.stdin); got != tc.want {
+				t.Errorf("combineStdin(%q, %q) = %q, want %q", tc.prompt, tc.stdin, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-cli/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultChunkPrompt = "Summarize this part (%d of %d) of a longer document. Keep the key facts, names and numbers."
+	defaultFinalPrompt = "Combine these partial summaries into one concise summary of the whole document."
+)
+
+var (
+	summarizeInput       string
+	summarizeProvider    string
+	summarizeModel       string
+	summarizeChunkTokens int
+	summarizeFinalPrompt string
+	summarizeJson        bool
+	summarizeFormat      string
+)
+
+type SummaryResult struct {
+	Provider string `json:"provider" yaml:"provider" xml:"provider"`
+	Chunks   int    `json:"chunks" yaml:"chunks" xml:"chunks"`
+	Requests int    `json:"requests" yaml:"requests" xml:"requests"`
+	Summary  string `json:"summary" yaml:"summary" xml:"summary"`
+}
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize",
+	Short: "Summarize a document too long for the model's context window",
+	Long: `Summarize a long document with map-reduce.
+
+The --input-file is split into chunks of at most --chunk-tokens tokens and
+each chunk is summarized. While the combined summaries are still larger than
+one chunk they are summarized again; the result is then condensed with
+--final-prompt. A document that fits in one chunk takes a single request.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		_, _ = loadDotenv()
+
+		format, err := resolveFormat(summarizeFormat, summarizeJson)
+		if err != nil {
+			return err
+		}
+		if summarizeChunkTokens < 100 {
+			return &usageError{err: fmt.Errorf("--chunk-tokens must be at least 100")}
+		}
+
+		data, err := os.ReadFile(summarizeInput)
+		if err != nil {
+			return fmt.Errorf("failed to read input file %s: %w", summarizeInput, err)
+		}
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			return &usageError{err: fmt.Errorf("input file %s is empty", summarizeInput)}
+		}
+
+		name := strings.ToLower(summarizeProvider)
+		provider, err := getProvider(name, "", summarizeModel)
+		if err != nil {
+			return fmt.Errorf("provider setup failed: %w", err)
+		}
+		provider = providers.Chain(provider, providerMiddlewares(name, nil)...)
+
+		// The tokenizer follows the model that will actually read the chunks.
+		tokenModel := firstNonEmpty(resolveModelAlias(name, summarizeModel), providers.DefaultModel(name))
+		result, err := summarizeText(cmd.Context(), provider, tokenModel, text)
+		if err != nil {
+			return err
+		}
+		result.Provider = name
+
+		if format != formatText {
+			data, _ := marshalStructured(format, result)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
+			return nil
+		}
+		fmt.Println(result.Summary)
+		return nil
+	},
+}
+
+// summarizeText runs the map-reduce passes over text, counting tokens with
+// model's tokenizer.
+func summarizeText(ctx context.Context, provider providers.Provider, model, text string) (SummaryResult, error) {
+	var result SummaryResult
+
+	ask := func(prompt string) (string, error) {
+		result.Requests++
+		out, err := provider.Generate(ctx, providers.Inputs{Prompt: prompt})
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(out.Content), nil
+	}
+
+	chunks := chunkText(text, model, summarizeChunkTokens)
+	result.Chunks = len(chunks)
+
+	for len(chunks) > 1 {
+		summaries := make([]string, 0, len(chunks))
+		for i, chunk := range chunks {
+			fmt.Fprintf(os.Stderr, "summarizing chunk %d/%d\n", i+1, len(chunks))
+			summary, err := ask(fmt.Sprintf(defaultChunkPrompt, i+1, len(chunks)) + "\n\n" + chunk)
+			if err != nil {
+				return result, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+			}
+			summaries = append(summaries, summary)
+		}
+		next := chunkText(strings.Join(summaries, "\n\n"), model, summarizeChunkTokens)
+		if len(next) >= len(chunks) {
+			return result, fmt.Errorf("summaries are not shrinking (%d chunks became %d); raise --chunk-tokens", len(chunks), len(next))
+		}
+		chunks = next
+	}
+
+	summary, err := ask(summarizeFinalPrompt + "\n\n" + chunks[0])
+	if err != nil {
+		return result, fmt.Errorf("final summary: %w", err)
+	}
+	result.Summary = summary
+	return result, nil
+}
+
+// chunkText splits text into pieces of at most budget tokens, breaking on
+// blank lines, then lines, and only cutting inside a line as a last resort.
+func chunkText(text, model string, budget int) []string {
+	fits := func(s string) bool {
+		n, _ := providers.CountTokens(model, s)
+		return n <= budget
+	}
+
+	var chunks []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+			current = nil
+		}
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		if fits(strings.Join(append(current, para), "\n\n")) {
+			current = append(current, para)
+			continue
+		}
+		flush()
+		if fits(para) {
+			current = append(current, para)
+			continue
+		}
+		chunks = append(chunks, splitOversized(para, fits, budget)...)
+	}
+	flush()
+	return chunks
+}
+
+// splitOversized breaks a paragraph larger than the budget by lines, and
+// lines still too long by length.
+func splitOversized(para string, fits func(string) bool, budget int) []string {
+	var pieces []string
+	var current string
+	for _, line := range strings.Split(para, "\n") {
+		candidate := line
+		if current != "" {
+			candidate = current + "\n" + line
+		}
+		if fits(candidate) {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			pieces = append(pieces, current)
+		}
+		current = ""
+
+		for !fits(line) {
+			cut := truncateUTF8(line, budget*4)
+			for len(cut) > 1 && !fits(cut) {
+				cut = truncateUTF8(cut, len(cut)*3/4)
+			}
+			pieces = append(pieces, cut)
+			line = line[len(cut):]
+		}
+		current = line
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+func init() {
+	summarizeCmd.Flags().StringVar(&summarizeInput, "input-file", "", "Document to summarize (required)")
+	summarizeCmd.Flags().StringVar(&summarizeProvider, "provider", "openai", "AI provider ("+strings.Join(providers.Names(), "|")+")")
+	summarizeCmd.Flags().StringVarP(&summarizeModel, "model", "m", "", "Model to use; also picks the tokenizer for chunking")
+	summarizeCmd.Flags().IntVar(&summarizeChunkTokens, "chunk-tokens", 3000, "Maximum tokens per chunk sent for summarization")
+	summarizeCmd.Flags().StringVar(&summarizeFinalPrompt, "final-prompt", defaultFinalPrompt, "Instruction for the final pass over the combined summaries")
+	summarizeCmd.Flags().BoolVar(&summarizeJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	summarizeCmd.Flags().StringVar(&summarizeFormat, "format", formatText, "Output format (text|json|yaml|xml)")
+
+	summarizeCmd.MarkFlagRequired("input-file")
+	rootCmd.AddCommand(summarizeCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"ai-cli/internal/providers"
+)
+
+var (
+	outputTemplateFlag string
+	outputTemplate     *template.Template
+)
+
+// parseOutputTemplate compiles --output-template, reading it from a file when
+// the value starts with "@". The template is also run once against an empty
+// CLIOutput so a misspelled field fails before any request is made.
+func parseOutputTemplate(value string) (*template.Template, error) {
+	text := value
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output template: %w", err)
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --output-template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, CLIOutput{Usage: &providers.Usage{}}); err != nil {
+		return nil, fmt.Errorf("invalid --output-template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderOutputTemplate executes the template against output, ending the
+// result with a newline like the plain text output.
+func renderOutputTemplate(tmpl *template.Template, output CLIOutput) (string, error) {
+	// Usage fields render as zero rather than failing when the provider
+	// doesn't report them.
+	if output.Usage == nil {
+		output.Usage = &providers.Usage{}
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, output); err != nil {
+		return "", fmt.Errorf("failed to render --output-template: %w", err)
+	}
+	rendered := sb.String()
+	if !strings.HasSuffix(rendered, "\n") {
+		rendered += "\n"
+	}
+	return rendered, nil
+}
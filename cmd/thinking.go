@@ -0,0 +1,38 @@
+package cmd
+
+import "strings"
+
+var (
+	stripThinkingFlag bool
+	thinkingStartFlag string
+	thinkingEndFlag   string
+)
+
+// stripThinking removes every start...end span from text. A start with no
+// matching end hides the rest of the text, and an end with no start (R1
+// sometimes omits the opening tag) hides everything before it.
+func stripThinking(text, start, end string) string {
+	var out strings.Builder
+	rest := text
+
+	if i, j := strings.Index(rest, end), strings.Index(rest, start); i >= 0 && (j < 0 || i < j) {
+		rest = rest[i+len(end):]
+	}
+
+	for {
+		i := strings.Index(rest, start)
+		if i < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:i])
+
+		j := strings.Index(rest[i+len(start):], end)
+		if j < 0 {
+			break
+		}
+		rest = rest[i+len(start)+j+len(end):]
+	}
+
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"ai-cli/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokensPrompt     string
+	tokensPromptFile string
+	tokensModel      string
+	tokensJson       bool
+	tokensFormat     string
+)
+
+type TokenCount struct {
+	Model      string `json:"model" yaml:"model" xml:"model"`
+	Tokens     int    `json:"tokens" yaml:"tokens" xml:"tokens"`
+	Characters int    `json:"characters" yaml:"characters" xml:"characters"`
+}
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Count the tokens a prompt uses for a model",
+	Long: `Count the tokens a prompt uses for a model.
+
+The prompt is read from --prompt, --prompt-file or stdin. OpenAI models are
+counted with their tiktoken encoding; other models use a length-based estimate.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := resolveFormat(tokensFormat, tokensJson)
+		if err != nil {
+			return err
+		}
+
+		text, err := readTokensInput()
+		if err != nil {
+			return err
+		}
+
+		tokens, exact := providers.CountTokens(tokensModel, text)
+		result := TokenCount{
+			Model:      tokensModel,
+			Tokens:     tokens,
+			Characters: utf8.RuneCountInString(text),
+		}
+
+		if format != formatText {
+			data, _ := marshalStructured(format, result)
+			fmt.Println(strings.TrimSuffix(string(data), "\n"))
+			return nil
+		}
+
+		if exact {
+			fmt.Println(result.Tokens)
+		} else {
+			fmt.Printf("%d (estimated)\n", result.Tokens)
+		}
+		return nil
+	},
+}
+
+func init() {
+	tokensCmd.Flags().StringVarP(&tokensPrompt, "prompt", "p", "", "Text to count")
+	tokensCmd.Flags().StringVar(&tokensPromptFile, "prompt-file", "", "Read the text to count from a file")
+	tokensCmd.Flags().StringVarP(&tokensModel, "model", "m", "gpt-4", "Model whose tokenizer to use")
+	tokensCmd.Flags().BoolVar(&tokensJson, "json", false, "Output in JSON format (shorthand for --format json)")
+	tokensCmd.Flags().StringVar(&tokensFormat, "format", formatText, "Output format (text|json|yaml|xml)")
+
+	tokensCmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file")
+	rootCmd.AddCommand(tokensCmd)
+}
+
+func readTokensInput() (string, error) {
+	switch {
+	case tokensPrompt != "":
+		return tokensPrompt, nil
+	case tokensPromptFile != "":
+		data, err := os.ReadFile(tokensPromptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file %s: %w", tokensPromptFile, err)
+		}
+		return string(data), nil
+	case !stdinIsTerminal():
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", &usageError{err: fmt.Errorf("provide text via --prompt, --prompt-file or stdin")}
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+
+	"ai-cli/internal/providers"
+)
+
+// printVisionCost writes the estimated vision token cost of each image, and
+// the total, to w. Only image headers are decoded, not the pixels.
+func printVisionCost(w io.Writer, inputs providers.Inputs) {
+	total := 0
+	for _, img := range inputs.Images {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+		if err != nil {
+			fmt.Fprintf(w, "%s: can't read dimensions: %v\n", img.Filename, err)
+			continue
+		}
+		tokens := providers.EstimateVisionTokens(cfg.Width, cfg.Height, inputs.ImageDetail)
+		total += tokens
+		fmt.Fprintf(w, "%s: %dx%d, ~%d tokens\n", img.Filename, cfg.Width, cfg.Height, tokens)
+	}
+
+	detail := inputs.ImageDetail
+	if detail == "" {
+		detail = "auto"
+	}
+	fmt.Fprintf(w, "estimated vision cost: ~%d tokens (%d image(s), detail=%s)\n", total, len(inputs.Images), detail)
+}
+
+var (
+	imageBase64Flag []string
+	imageMimeFlag   string
+	dedupeImages    bool
+)
+
+// imageMimeExtensions maps the accepted --image-mime values to the file
+// extension providers use to label the data.
+var imageMimeExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// decodeBase64Images turns --image-base64 values into inputs without
+// touching the filesystem. Each gets a synthetic name carrying the
+// --image-mime extension so providers label it correctly.
+func decodeBase64Images(values []string, mime string) ([]providers.FileInput, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	if !strings.Contains(mime, "/") {
+		mime = "image/" + mime
+	}
+	ext, ok := imageMimeExtensions[mime]
+	if !ok {
+		return nil, fmt.Errorf("unsupported --image-mime %q (image/png, image/jpeg, image/gif or image/webp)", mime)
+	}
+
+	images := make([]providers.FileInput, 0, len(values))
+	for i, value := range values {
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("--image-base64 #%d is not valid base64: %w", i+1, err)
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("--image-base64 #%d is empty", i+1)
+		}
+		images = append(images, providers.FileInput{
+			Data:     data,
+			Filename: fmt.Sprintf("image-base64-%d%s", i+1, ext),
+		})
+	}
+	return images, nil
+}
+
+// dedupeImageInputs drops images whose bytes exactly match an earlier one,
+// e.g. the same file reached through two globs, and reports how many went.
+func dedupeImageInputs(images []providers.FileInput) ([]providers.FileInput, int) {
+	seen := make(map[[sha256.Size]byte]bool, len(images))
+	unique := images[:0:0]
+	for _, img := range images {
+		sum := sha256.Sum256(img.Data)
+		if seen[sum] {
+			continue
+		}
+		seen[sum] = true
+		unique = append(unique, img)
+	}
+	return unique, len(images) - len(unique)
+}
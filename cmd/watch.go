@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce collapses the burst of events editors emit on a single save.
+const watchDebounce = 300 * time.Millisecond
+
+// watchFile calls run once, then again each time path changes, until ctx is
+// cancelled. Errors from run are reported but don't stop the loop.
+func watchFile(ctx context.Context, path string, run func() error) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory: many editors save by writing a new file and
+	// renaming it over the old one, which drops a watch on the file itself.
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	rerun := func() {
+		if stdoutIsTerminal() {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := run(); err != nil {
+			var silentErr *silentError
+			if !errors.As(err, &silentErr) {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "\nWatching %s for changes (Ctrl-C to stop)...\n", path)
+	}
+
+	rerun()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != absPath || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			debounce = time.After(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, "watch error:", err)
+		case <-debounce:
+			debounce = nil
+			rerun()
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AWSCredentials sign requests to AWS services with SigV4.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // set for temporary credentials
+}
+
+// AWSConfig holds what an AWS-signed provider needs in place of an API key.
+// Credentials are looked up in the usual order: the static keys here (from
+// AWS_ACCESS_KEY_ID and friends), then Profile in the shared credentials
+// file, then the EC2 instance metadata service. An empty Region falls back
+// to the profile's region in the shared config file.
+type AWSConfig struct {
+	Region string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	Profile         string // empty means "default"
+	CredentialsFile string // empty means ~/.aws/credentials
+	ConfigFile      string // empty means ~/.aws/config
+}
+
+// imdsBaseURL is the EC2 instance metadata service; imdsTimeout keeps the
+// lookup from stalling commands run off EC2, where nothing answers.
+const (
+	imdsBaseURL = "http://169.254.169.254/latest"
+	imdsTimeout = time.Second
+)
+
+// resolveAWSCredentials walks the credential chain and returns the first
+// credentials found.
+func resolveAWSCredentials(ctx context.Context, config AWSConfig) (AWSCredentials, error) {
+	if config.AccessKeyID != "" || config.SecretAccessKey != "" {
+		if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+			return AWSCredentials{}, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set together")
+		}
+		return AWSCredentials{config.AccessKeyID, config.SecretAccessKey, config.SessionToken}, nil
+	}
+
+	creds, err := sharedAWSCredentials(config)
+	if err == nil {
+		return creds, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return AWSCredentials{}, err
+	}
+
+	creds, imdsErr := imdsCredentials(ctx)
+	if imdsErr != nil {
+		return AWSCredentials{}, fmt.Errorf("no AWS credentials found: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, "+
+			"add a profile to ~/.aws/credentials, or run on EC2 with an instance role (%v)", imdsErr)
+	}
+	return creds, nil
+}
+
+// sharedAWSCredentials reads config.Profile from the shared credentials
+// file. A missing file or profile is reported as os.ErrNotExist so the
+// chain moves on.
+func sharedAWSCredentials(config AWSConfig) (AWSCredentials, error) {
+	path := awsFilePath(config.CredentialsFile, "credentials")
+	if path == "" {
+		return AWSCredentials{}, os.ErrNotExist
+	}
+	profile := awsProfile(config)
+
+	values, err := readAWSSection(path, profile)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	creds := AWSCredentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("profile %q in %s has no aws_access_key_id/aws_secret_access_key", profile, path)
+	}
+	return creds, nil
+}
+
+// sharedAWSRegion returns the region of config.Profile in the shared config
+// file, or "" when the file, profile or setting is missing. The config file
+// names profiles other than default "[profile NAME]".
+func sharedAWSRegion(config AWSConfig) string {
+	path := awsFilePath(config.ConfigFile, "config")
+	if path == "" {
+		return ""
+	}
+	section := awsProfile(config)
+	if section != "default" {
+		section = "profile " + section
+	}
+	values, err := readAWSSection(path, section)
+	if err != nil {
+		return ""
+	}
+	return values["region"]
+}
+
+func awsProfile(config AWSConfig) string {
+	if config.Profile == "" {
+		return "default"
+	}
+	return config.Profile
+}
+
+// awsFilePath returns path, or ~/.aws/name when path is empty.
+func awsFilePath(path, name string) string {
+	if path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// readAWSSection returns the key = value pairs of one [section] of an AWS
+// INI file, or os.ErrNotExist when the file or section is missing.
+func readAWSSection(path, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	found, inSection := false, false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.Join(strings.Fields(line[1:len(line)-1]), " ") == section
+			found = found || inSection
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if inSection && ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return values, nil
+}
+
+// imdsCredentials fetches the instance role's temporary credentials with
+// IMDSv2: a session token first, then the role name, then its keys.
+func imdsCredentials(ctx context.Context) (AWSCredentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+	client := &http.Client{}
+
+	get := func(method, path string, header http.Header) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, method, imdsBaseURL+path, nil)
+		if err != nil {
+			return "", err
+		}
+		for key, vals := range header {
+			req.Header[key] = vals
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("instance metadata %s: HTTP %d", path, resp.StatusCode)
+		}
+		return strings.TrimSpace(string(body)), nil
+	}
+
+	token, err := get(http.MethodPut, "/api/token", http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"21600"}})
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	auth := http.Header{"X-Aws-Ec2-Metadata-Token": {token}}
+	roles, err := get(http.MethodGet, "/meta-data/iam/security-credentials/", auth)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	role, _, _ := strings.Cut(roles, "\n")
+	if role == "" {
+		return AWSCredentials{}, fmt.Errorf("instance has no IAM role")
+	}
+	body, err := get(http.MethodGet, "/meta-data/iam/security-credentials/"+role, auth)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+
+	var doc struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		Token           string `json:"Token"`
+	}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return AWSCredentials{}, fmt.Errorf("instance metadata credentials: %w", err)
+	}
+	return AWSCredentials{doc.AccessKeyID, doc.SecretAccessKey, doc.Token}, nil
+}
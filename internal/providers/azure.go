@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+	"net/url"
+)
+
+/*
+=== Azure OpenAI ===
+Azure serves OpenAI models as named deployments on a per-resource endpoint:
+
+	POST https://NAME.openai.azure.com/openai/deployments/DEPLOYMENT/chat/completions?api-version=2024-10-21
+
+Config.BaseURL is the resource endpoint, Config.Model the deployment name
+(the deployment fixes the model, so there is no default), and the key is
+sent in the api-key header. Request and response bodies match OpenAI's, so
+the OpenAI implementation does the work. Vision, log probabilities and
+structured outputs depend on the deployed model and api-version.
+
+Model listing is not supported: deployments are managed through Azure's
+control plane, not the key-authenticated data plane.
+*/
+
+const azureDefaultAPIVersion = "2024-10-21"
+
+type AzureOpenAI struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:       "azure",
+		APIKeyEnv:  "AZURE_OPENAI_API_KEY",
+		BaseURLEnv: "AZURE_OPENAI_ENDPOINT",
+		ModelEnv:   "AZURE_OPENAI_DEPLOYMENT",
+		New:        func(config Config) Provider { return NewAzureOpenAI(config) },
+	})
+}
+
+func NewAzureOpenAI(config Config) *AzureOpenAI {
+	p := newOpenAICompatible(config, openAIService{name: "azure"})
+	p.azure = true
+	return &AzureOpenAI{openai: p}
+}
+
+func (p *AzureOpenAI) Supports(feature Feature) bool {
+	return p.openai.Supports(feature)
+}
+
+func (p *AzureOpenAI) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
+
+// azureURL returns the URL of endpoint on deployment.
+func azureURL(config Config, deployment, endpoint string) string {
+	version := config.APIVersion
+	if version == "" {
+		version = azureDefaultAPIVersion
+	}
+	return baseURL(config, "") + "/openai/deployments/" + url.PathEscape(deployment) +
+		endpoint + "?api-version=" + url.QueryEscape(version)
+}
@@ -0,0 +1,285 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+=== AWS Bedrock ===
+Uses the Converse API, which gives Claude, Titan, Llama, Mistral and other
+Bedrock models one request format:
+
+	POST https://bedrock-runtime.REGION.amazonaws.com/model/MODEL_ID/converse
+
+Config.BedrockAPI = BedrockInvoke switches to InvokeModel (.../invoke) with
+the model's native body instead, for models and request fields Converse
+doesn't cover; see bedrock_invoke.go.
+
+Requests are signed with SigV4 using AWS credentials (see AWSConfig) rather
+than an API key. Model access must be enabled per model in the Bedrock
+console; newer models are only served through cross-region inference
+profiles, e.g. us.anthropic.claude-3-7-sonnet-20250219-v1:0.
+
+- anthropic.claude-3-5-sonnet-20240620-v1:0: default, text and images (200K context)
+- anthropic.claude-3-haiku-20240307-v1:0: fast and cheap, text and images (200K context)
+- amazon.titan-text-premier-v1:0: text only, no system prompt (32K context)
+*/
+
+const (
+	bedrockDefaultModel   = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	bedrockDefaultRegion  = "us-east-1"
+	bedrockDefaultTimeout = 60 * time.Second
+	bedrockService        = "bedrock"
+)
+
+type Bedrock struct {
+	config Config
+	client *http.Client
+
+	mu    sync.Mutex
+	creds *AWSCredentials // resolved on first use; IMDS needs a context
+
+	regionOnce sync.Once
+	regionName string
+}
+
+type bedrockError struct {
+	Message string `json:"message"`
+}
+
+type bedrockContent struct {
+	Text  string        `json:"text,omitempty"`
+	Image *bedrockImage `json:"image,omitempty"`
+}
+
+type bedrockImage struct {
+	Format string `json:"format"`
+	Source struct {
+		Bytes []byte `json:"bytes"` // base64 in JSON
+	} `json:"source"`
+}
+
+type bedrockMessage struct {
+	Role    string           `json:"role"`
+	Content []bedrockContent `json:"content"`
+}
+
+func init() {
+	Register(Registration{
+		Name:         "bedrock",
+		DefaultModel: bedrockDefaultModel,
+		New:          func(config Config) Provider { return NewBedrock(config) },
+	})
+}
+
+func NewBedrock(config Config) *Bedrock {
+	return &Bedrock{
+		config: config,
+		client: newHTTPClient(config, requestTimeout(config, bedrockDefaultTimeout)),
+	}
+}
+
+// Supports reports vision as available; whether a model accepts images is
+// up to the model, and Bedrock rejects those that don't.
+func (p *Bedrock) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration || feature == FeatureVision
+}
+
+func (p *Bedrock) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	creds, err := p.credentials(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	if p.config.BedrockAPI == BedrockInvoke {
+		return p.invoke(ctx, creds, inputs)
+	}
+
+	content := []bedrockContent{}
+	if strings.TrimSpace(inputs.Prompt) != "" || len(inputs.Images) == 0 {
+		content = append(content, bedrockContent{Text: inputs.Prompt})
+	}
+	for _, img := range inputs.Images {
+		image := &bedrockImage{Format: bedrockImageFormat(img.Filename)}
+		image.Source.Bytes = img.Data
+		content = append(content, bedrockContent{Image: image})
+	}
+
+	var system []bedrockContent
+	if p.config.SystemPrompt != "" {
+		system = append(system, bedrockContent{Text: p.config.SystemPrompt})
+	}
+	var messages []bedrockMessage
+	for _, msg := range inputs.Messages {
+		if msg.Role == "system" {
+			system = append(system, bedrockContent{Text: msg.Content})
+			continue
+		}
+		messages = append(messages, bedrockMessage{Role: msg.Role, Content: []bedrockContent{{Text: msg.Content}}})
+	}
+	messages = append(messages, bedrockMessage{Role: "user", Content: content})
+
+	inference := map[string]any{"maxTokens": maxTokens(p.config)}
+	if p.config.Temperature != nil {
+		inference["temperature"] = *p.config.Temperature
+	}
+	payload := map[string]any{
+		"messages":        messages,
+		"inferenceConfig": inference,
+	}
+	if len(system) > 0 {
+		payload["system"] = system
+	}
+	applyExtraParams(p.config, payload)
+
+	body, header, err := p.post(ctx, creds, "converse", payload, len(inputs.Images))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var response struct {
+		Output struct {
+			Message struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"output"`
+		Usage *struct {
+			InputTokens  int `json:"inputTokens"`
+			OutputTokens int `json:"outputTokens"`
+			TotalTokens  int `json:"totalTokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Result{}, fmt.Errorf("response parsing failed: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range response.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	if text.Len() == 0 {
+		return Result{}, ErrNoContent
+	}
+
+	result := Result{
+		Content:   text.String(),
+		RequestID: requestIDFromHeader(header),
+		Model:     p.getModel(),
+	}
+	if u := response.Usage; u != nil {
+		result.Usage = &Usage{PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
+	}
+	return result, nil
+}
+
+// post sends payload to the model's action ("converse" or "invoke") and
+// returns the body of a successful response.
+func (p *Bedrock) post(ctx context.Context, creds AWSCredentials, action string, payload any, images int) ([]byte, http.Header, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal error: %w", err)
+	}
+
+	reqBody, contentEncoding := encodeRequestBody(p.config, jsonData)
+	logPayloadSize(p.config, "bedrock", jsonData, reqBody, images)
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint()+"/model/"+awsEscape(p.getModel())+"/"+action, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("request creation failed: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	setCommonHeaders(req, p.config)
+	signV4(req, reqBody, creds, p.region(), bedrockService, time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body, p.config.MaxResponseBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// X-Amzn-ErrorType is e.g. "ThrottlingException:http://internal.amazon.com/...".
+		code, _, _ := strings.Cut(resp.Header.Get("X-Amzn-ErrorType"), ":")
+		apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Code: code, Message: errorBodyMessage(resp, body, p.config)}
+		var apiError bedrockError
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			apiErr.Message = apiError.Message
+		}
+		return nil, nil, apiErr
+	}
+	return body, resp.Header, nil
+}
+
+// credentials resolves the AWS credentials once per provider.
+func (p *Bedrock) credentials(ctx context.Context) (AWSCredentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.creds != nil {
+		return *p.creds, nil
+	}
+	creds, err := resolveAWSCredentials(ctx, p.config.AWS)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	p.creds = &creds
+	return creds, nil
+}
+
+// region is AWS_REGION, else the profile's region in the shared config
+// file, else us-east-1. The file is read once per provider.
+func (p *Bedrock) region() string {
+	p.regionOnce.Do(func() {
+		p.regionName = p.config.AWS.Region
+		if p.regionName == "" {
+			p.regionName = sharedAWSRegion(p.config.AWS)
+		}
+		if p.regionName == "" {
+			p.regionName = bedrockDefaultRegion
+		}
+	})
+	return p.regionName
+}
+
+func (p *Bedrock) endpoint() string {
+	return baseURL(p.config, "https://bedrock-runtime."+p.region()+".amazonaws.com")
+}
+
+func (p *Bedrock) getModel() string {
+	if p.config.Model != "" {
+		return p.config.Model
+	}
+	return bedrockDefaultModel
+}
+
+// bedrockImageFormat maps a file name to one of the formats Converse
+// accepts: png, jpeg, gif or webp.
+func bedrockImageFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png":
+		return "png"
+	case ".gif":
+		return "gif"
+	case ".webp":
+		return "webp"
+	default:
+		return "jpeg"
+	}
+}
@@ -0,0 +1,261 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Bedrock APIs for Config.BedrockAPI.
+const (
+	BedrockConverse = "converse"
+	BedrockInvoke   = "invoke"
+)
+
+// invoke calls InvokeModel, whose request and response bodies are the
+// model vendor's own. Anthropic and Amazon Nova bodies carry images and
+// conversation turns; Titan, Llama, Mistral and unknown models take a plain
+// prompt, to which --extra-params can add vendor fields.
+func (p *Bedrock) invoke(ctx context.Context, creds AWSCredentials, inputs Inputs) (Result, error) {
+	vendor := bedrockVendor(p.getModel())
+
+	var payload map[string]any
+	switch {
+	case vendor == "anthropic":
+		payload = p.anthropicBody(inputs)
+	case strings.HasPrefix(vendor, "amazon.nova"):
+		payload = p.novaBody(inputs)
+	default:
+		if len(inputs.Images) > 0 || len(inputs.Messages) > 0 {
+			return Result{}, fmt.Errorf("bedrock InvokeModel only sends images and --messages-file to Anthropic and Nova models; use the converse API for %s", p.getModel())
+		}
+		payload = p.promptBody(vendor, inputs.Prompt)
+	}
+	applyExtraParams(p.config, payload)
+
+	body, header, err := p.post(ctx, creds, "invoke", payload, len(inputs.Images))
+	if err != nil {
+		return Result{}, err
+	}
+	return parseInvokeResponse(body, header, p.getModel())
+}
+
+// bedrockVendor returns the vendor part of a model ID, dropping any
+// cross-region inference prefix: us.anthropic.claude-... is "anthropic".
+// Amazon Nova is reported as "amazon.nova" because its body differs from
+// Titan's.
+func bedrockVendor(modelID string) string {
+	id := modelID
+	for _, prefix := range []string{"us.", "eu.", "apac.", "us-gov.", "global."} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	if strings.HasPrefix(id, "amazon.nova") {
+		return "amazon.nova"
+	}
+	vendor, _, _ := strings.Cut(id, ".")
+	return vendor
+}
+
+func (p *Bedrock) anthropicBody(inputs Inputs) map[string]any {
+	var system []string
+	if p.config.SystemPrompt != "" {
+		system = append(system, p.config.SystemPrompt)
+	}
+	var messages []map[string]any
+	for _, msg := range inputs.Messages {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+			continue
+		}
+		messages = append(messages, map[string]any{"role": msg.Role, "content": msg.Content})
+	}
+
+	var content []map[string]any
+	for _, img := range inputs.Images {
+		content = append(content, map[string]any{
+			"type": "image",
+			"source": map[string]any{
+				"type":       "base64",
+				"media_type": "image/" + bedrockImageFormat(img.Filename),
+				"data":       img.Data, // []byte marshals as base64
+			},
+		})
+	}
+	if strings.TrimSpace(inputs.Prompt) != "" || len(inputs.Images) == 0 {
+		content = append(content, map[string]any{"type": "text", "text": inputs.Prompt})
+	}
+	messages = append(messages, map[string]any{"role": "user", "content": content})
+
+	payload := map[string]any{
+		"anthropic_version": "bedrock-2023-05-31",
+		"max_tokens":        maxTokens(p.config),
+		"messages":          messages,
+	}
+	if len(system) > 0 {
+		payload["system"] = strings.Join(system, "\n\n")
+	}
+	if p.config.Temperature != nil {
+		payload["temperature"] = *p.config.Temperature
+	}
+	return payload
+}
+
+// novaBody uses Nova's messages-v1 schema, which mirrors Converse.
+func (p *Bedrock) novaBody(inputs Inputs) map[string]any {
+	var system []bedrockContent
+	if p.config.SystemPrompt != "" {
+		system = append(system, bedrockContent{Text: p.config.SystemPrompt})
+	}
+	var messages []bedrockMessage
+	for _, msg := range inputs.Messages {
+		if msg.Role == "system" {
+			system = append(system, bedrockContent{Text: msg.Content})
+			continue
+		}
+		messages = append(messages, bedrockMessage{Role: msg.Role, Content: []bedrockContent{{Text: msg.Content}}})
+	}
+	content := []bedrockContent{}
+	for _, img := range inputs.Images {
+		image := &bedrockImage{Format: bedrockImageFormat(img.Filename)}
+		image.Source.Bytes = img.Data
+		content = append(content, bedrockContent{Image: image})
+	}
+	if strings.TrimSpace(inputs.Prompt) != "" || len(inputs.Images) == 0 {
+		content = append(content, bedrockContent{Text: inputs.Prompt})
+	}
+	messages = append(messages, bedrockMessage{Role: "user", Content: content})
+
+	inference := map[string]any{"maxTokens": maxTokens(p.config)}
+	if p.config.Temperature != nil {
+		inference["temperature"] = *p.config.Temperature
+	}
+	payload := map[string]any{
+		"schemaVersion":   "messages-v1",
+		"messages":        messages,
+		"inferenceConfig": inference,
+	}
+	if len(system) > 0 {
+		payload["system"] = system
+	}
+	return payload
+}
+
+// promptBody builds the single-prompt bodies of Titan, Llama, Mistral and,
+// as a best guess, any other vendor.
+func (p *Bedrock) promptBody(vendor, prompt string) map[string]any {
+	if p.config.SystemPrompt != "" {
+		prompt = p.config.SystemPrompt + "\n\n" + prompt
+	}
+	switch vendor {
+	case "amazon":
+		generation := map[string]any{"maxTokenCount": maxTokens(p.config)}
+		if p.config.Temperature != nil {
+			generation["temperature"] = *p.config.Temperature
+		}
+		return map[string]any{"inputText": prompt, "textGenerationConfig": generation}
+	case "meta":
+		payload := map[string]any{"prompt": prompt, "max_gen_len": maxTokens(p.config)}
+		if p.config.Temperature != nil {
+			payload["temperature"] = *p.config.Temperature
+		}
+		return payload
+	case "mistral":
+		prompt = "<s>[INST] " + prompt + " [/INST]"
+	}
+	payload := map[string]any{"prompt": prompt, "max_tokens": maxTokens(p.config)}
+	if p.config.Temperature != nil {
+		payload["temperature"] = *p.config.Temperature
+	}
+	return payload
+}
+
+// invokeResponse covers the response bodies of the vendors invoke builds
+// requests for; only the fields of the answering vendor are set.
+type invokeResponse struct {
+	// Anthropic
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage *struct {
+		InputTokens      int `json:"input_tokens"`
+		OutputTokens     int `json:"output_tokens"`
+		NovaInputTokens  int `json:"inputTokens"`
+		NovaOutputTokens int `json:"outputTokens"`
+	} `json:"usage"`
+	// Amazon Nova
+	Output struct {
+		Message struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	} `json:"output"`
+	// Meta Llama
+	Generation           string `json:"generation"`
+	PromptTokenCount     int    `json:"prompt_token_count"`
+	GenerationTokenCount int    `json:"generation_token_count"`
+	// Amazon Titan
+	InputTextTokenCount int `json:"inputTextTokenCount"`
+	Results             []struct {
+		OutputText string `json:"outputText"`
+		TokenCount int    `json:"tokenCount"`
+	} `json:"results"`
+	// Mistral
+	Outputs []struct {
+		Text string `json:"text"`
+	} `json:"outputs"`
+}
+
+func parseInvokeResponse(body []byte, header http.Header, model string) (Result, error) {
+	var response invokeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Result{}, fmt.Errorf("response parsing failed: %w", err)
+	}
+
+	var text strings.Builder
+	var usage *Usage
+	for _, block := range response.Content {
+		if block.Type == "" || block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	for _, block := range response.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	text.WriteString(response.Generation)
+	for _, r := range response.Results {
+		text.WriteString(r.OutputText)
+	}
+	for _, o := range response.Outputs {
+		text.WriteString(o.Text)
+	}
+	if text.Len() == 0 {
+		return Result{}, ErrNoContent
+	}
+
+	switch {
+	case response.Usage != nil:
+		in := response.Usage.InputTokens + response.Usage.NovaInputTokens
+		out := response.Usage.OutputTokens + response.Usage.NovaOutputTokens
+		usage = &Usage{PromptTokens: in, CompletionTokens: out, TotalTokens: in + out}
+	case response.PromptTokenCount > 0 || response.GenerationTokenCount > 0:
+		usage = &Usage{PromptTokens: response.PromptTokenCount, CompletionTokens: response.GenerationTokenCount,
+			TotalTokens: response.PromptTokenCount + response.GenerationTokenCount}
+	case len(response.Results) > 0:
+		out := 0
+		for _, r := range response.Results {
+			out += r.TokenCount
+		}
+		usage = &Usage{PromptTokens: response.InputTextTokenCount, CompletionTokens: out, TotalTokens: response.InputTextTokenCount + out}
+	}
+
+	return Result{
+		Content:   text.String(),
+		RequestID: requestIDFromHeader(header),
+		Model:     model,
+		Usage:     usage,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testAWS = AWSConfig{Region: "us-west-2", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+func TestBedrockInvoke(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		response string
+		check    func(t *testing.T, body map[string]any)
+		want     string
+		tokens   int
+	}{
+		{
+			name:     "anthropic",
+			model:    "us.anthropic.claude-3-5-sonnet-20240620-v1:0",
+			response: `{"content":[{"type":"text","text":"hi from claude"}],"usage":{"input_tokens":5,"output_tokens":3}}`,
+			check: func(t *testing.T, body map[string]any) {
+				if body["anthropic_version"] != "bedrock-2023-05-31" || body["system"] != "be brief" {
+					t.Errorf("body = %v", body)
+				}
+			},
+			want:   "hi from claude",
+			tokens: 8,
+		},
+		{
+			name:     "llama",
+			model:    "meta.llama3-70b-instruct-v1:0",
+			response: `{"generation":"hi from llama","prompt_token_count":4,"generation_token_count":2}`,
+			check: func(t *testing.T, body map[string]any) {
+				if body["prompt"] != "be brief\n\nhi" || body["max_gen_len"] == nil {
+					t.Errorf("body = %v", body)
+				}
+			},
+			want:   "hi from llama",
+			tokens: 6,
+		},
+		{
+			name:     "titan",
+			model:    "amazon.titan-text-premier-v1:0",
+			response: `{"inputTextTokenCount":4,"results":[{"tokenCount":2,"outputText":"hi from titan"}]}`,
+			check: func(t *testing.T, body map[string]any) {
+				if body["inputText"] == nil || body["textGenerationConfig"] == nil {
+					t.Errorf("body = %v", body)
+				}
+			},
+			want:   "hi from titan",
+			tokens: 6,
+		},
+		{
+			name:     "nova",
+			model:    "amazon.nova-lite-v1:0",
+			response: `{"output":{"message":{"content":[{"text":"hi from nova"}]}},"usage":{"inputTokens":4,"outputTokens":3}}`,
+			check: func(t *testing.T, body map[string]any) {
+				if body["schemaVersion"] != "messages-v1" {
+					t.Errorf("body = %v", body)
+				}
+			},
+			want:   "hi from nova",
+			tokens: 7,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/model/" + tc.model + "/invoke"; r.URL.Path != want {
+					t.Errorf("path = %s, want %s", r.URL.Path, want)
+				}
+				if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+					t.Errorf("Authorization = %q, not signed for us-west-2 bedrock", auth)
+				}
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decoding body: %v", err)
+				}
+				tc.check(t, body)
+				w.Write([]byte(tc.response))
+			}))
+			defer srv.Close()
+
+			p := NewBedrock(Config{BaseURL: srv.URL, Model: tc.model, AWS: testAWS, BedrockAPI: BedrockInvoke, SystemPrompt: "be brief"})
+			result, err := p.Generate(context.Background(), Inputs{Prompt: "hi"})
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if result.Content != tc.want || result.Usage == nil || result.Usage.TotalTokens != tc.tokens {
+				t.Errorf("result = %+v usage = %+v, want %q with %d tokens", result, result.Usage, tc.want, tc.tokens)
+			}
+		})
+	}
+}
+
+func TestBedrockInvokeRejectsImagesForPromptModels(t *testing.T) {
+	p := NewBedrock(Config{BaseURL: "http://127.0.0.1:0", Model: "meta.llama3-70b-instruct-v1:0", AWS: testAWS, BedrockAPI: BedrockInvoke})
+	_, err := p.Generate(context.Background(), Inputs{Prompt: "hi", Images: []FileInput{{Data: []byte("x"), Filename: "a.png"}}})
+	if err == nil || !strings.Contains(err.Error(), "converse") {
+		t.Errorf("err = %v, want a hint to use converse", err)
+	}
+}
+
+func TestBedrockRegionFromSharedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := "[default]\nregion = eu-west-1\n\n[profile work]\nregion = ap-southeast-2\noutput = json\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		aws  AWSConfig
+		want string
+	}{
+		{"default profile", AWSConfig{ConfigFile: path}, "eu-west-1"},
+		{"named profile", AWSConfig{ConfigFile: path, Profile: "work"}, "ap-southeast-2"},
+		{"explicit region wins", AWSConfig{ConfigFile: path, Region: "us-west-2"}, "us-west-2"},
+		{"missing profile", AWSConfig{ConfigFile: path, Profile: "nope"}, bedrockDefaultRegion},
+		{"missing file", AWSConfig{ConfigFile: path + ".missing"}, bedrockDefaultRegion},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NewBedrock(Config{AWS: tc.aws}).region(); got != tc.want {
+				t.Errorf("region = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBedrockConverse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model/"+bedrockDefaultModel+"/converse" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Header().Set("X-Amzn-Requestid", "aws-req")
+		w.Write([]byte(`{"output":{"message":{"content":[{"text":"hello"}]}},"usage":{"inputTokens":2,"outputTokens":1,"totalTokens":3}}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewBedrock(Config{BaseURL: srv.URL, AWS: testAWS}).Generate(context.Background(), Inputs{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if result.Content != "hello" || result.RequestID != "aws-req" || result.Usage.TotalTokens != 3 {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,123 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the provider while its
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreaker stops calling a provider after Threshold consecutive
+// failures within Window. While open every call fails immediately; after
+// Cooldown one probe request is let through (half-open) and its outcome
+// closes or re-opens the circuit.
+type CircuitBreaker struct {
+	Threshold int
+	Window    time.Duration
+	Cooldown  time.Duration
+
+	mu           sync.Mutex
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+func NewCircuitBreaker(threshold int, window, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Window: window, Cooldown: cooldown}
+}
+
+// allow reports whether a call may proceed, and whether it is the
+// half-open probe.
+func (b *CircuitBreaker) allow() (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true, false
+	}
+	if b.probing || time.Since(b.openedAt) < b.Cooldown {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// cancelProbe gives up a probe that ended without an outcome, such as a
+// cancelled call, so the next call can probe instead.
+func (b *CircuitBreaker) cancelProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures, b.openedAt, b.probing = 0, time.Time{}, false
+		return
+	}
+
+	if b.probing {
+		// The half-open probe failed; wait out another cooldown.
+		b.openedAt, b.probing = time.Now(), false
+		return
+	}
+
+	now := time.Now()
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.Window {
+		b.failures, b.firstFailure = 0, now
+	}
+	b.failures++
+	if b.failures >= b.Threshold {
+		b.openedAt = now
+	}
+}
+
+// WithCircuitBreaker fails calls fast while b is open. Only failures that
+// point at the provider (network errors, throttling, 5xx) count; rejected
+// input and cancellation don't.
+func WithCircuitBreaker(name string, b *CircuitBreaker) ProviderMiddleware {
+	return func(next Provider) Provider {
+		return &generateFunc{
+			Provider: next,
+			generate: func(ctx context.Context, inputs Inputs) (Result, error) {
+				ok, probe := b.allow()
+				if !ok {
+					return Result{}, fmt.Errorf("%s: %w after %d consecutive failures", name, ErrCircuitOpen, b.Threshold)
+				}
+
+				result, err := next.Generate(ctx, inputs)
+				if err != nil && ctx.Err() != nil {
+					if probe {
+						b.cancelProbe()
+					}
+					return result, err
+				}
+				b.record(isProviderFailure(err))
+				return result, err
+			},
+		}
+	}
+}
+
+func isProviderFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRateLimit() || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// A half-open probe that is cancelled must not leave the circuit refusing
+// every later call.
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	b := NewCircuitBreaker(1, time.Minute, time.Millisecond)
+	stub := &stubProvider{
+		results: []Result{{}, {}, {Content: "ok"}},
+		errs:    []error{&APIError{StatusCode: 503}, context.Canceled, nil},
+	}
+	p := WithCircuitBreaker("test", b)(stub)
+
+	if _, err := p.Generate(context.Background(), Inputs{}); err == nil {
+		t.Fatal("first call should fail")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Generate(ctx, Inputs{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("probe err = %v, want context.Canceled", err)
+	}
+
+	result, err := p.Generate(context.Background(), Inputs{})
+	if err != nil || result.Content != "ok" {
+		t.Fatalf("call after cancelled probe = %q, %v; want a new probe to go through", result.Content, err)
+	}
+	if stub.calls != 3 {
+		t.Errorf("calls = %d, want 3", stub.calls)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	b := NewCircuitBreaker(2, time.Minute, 10*time.Millisecond)
+	stub := &stubProvider{
+		results: []Result{{}, {}, {Content: "ok"}},
+		errs:    []error{&APIError{StatusCode: 500}, &APIError{StatusCode: 500}, nil},
+	}
+	p := WithCircuitBreaker("test", b)(stub)
+
+	p.Generate(context.Background(), Inputs{})
+	p.Generate(context.Background(), Inputs{})
+	if _, err := p.Generate(context.Background(), Inputs{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("open circuit reached the provider: calls = %d", stub.calls)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if result, err := p.Generate(context.Background(), Inputs{}); err != nil || result.Content != "ok" {
+		t.Fatalf("probe = %q, %v", result.Content, err)
+	}
+	if ok, _ := b.allow(); !ok {
+		t.Error("circuit still open after a successful probe")
+	}
+}
@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/*
+=== Cohere ===
+Chat API v2 (POST /v2/chat). v2 replaces v1's preamble with an ordinary
+system message, so Config.SystemPrompt covers it; other Cohere parameters
+(documents, citation_options, safety_mode) go through Config.ExtraParams.
+
+- command-r-08-2024: RAG and tool use, default (128K context)
+- command-r-plus-08-2024: stronger reasoning over long documents (128K context)
+- command-r7b-12-2024: small and fast (128K context)
+- command-a-03-2025: flagship (256K context)
+*/
+
+const (
+	cohereBaseURL        = "https://api.cohere.com"
+	cohereDefaultModel   = "command-r-08-2024"
+	cohereDefaultTimeout = 30 * time.Second
+)
+
+type Cohere struct {
+	config Config
+	client *http.Client
+}
+
+type cohereError struct {
+	Message string `json:"message"`
+}
+
+func init() {
+	Register(Registration{
+		Name:           "cohere",
+		APIKeyEnv:      "CO_API_KEY",
+		DefaultModel:   cohereDefaultModel,
+		DefaultBaseURL: cohereBaseURL,
+		New:            func(config Config) Provider { return NewCohere(config) },
+	})
+}
+
+func NewCohere(config Config) *Cohere {
+	return &Cohere{
+		config: config,
+		client: newHTTPClient(config, requestTimeout(config, cohereDefaultTimeout)),
+	}
+}
+
+func (p *Cohere) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration
+}
+
+func (p *Cohere) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	if len(inputs.Images) > 0 {
+		return Result{}, fmt.Errorf("Cohere does not support image analysis")
+	}
+
+	payload := map[string]any{
+		"model":    p.getModel(),
+		"messages": chatMessages(p.config, inputs.Messages, inputs.Prompt),
+	}
+	applySampling(p.config, payload)
+	applyExtraParams(p.config, payload)
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal error: %w", err)
+	}
+
+	reqBody, contentEncoding := encodeRequestBody(p.config, jsonData)
+	logPayloadSize(p.config, "cohere", jsonData, reqBody, 0)
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL(p.config, cohereBaseURL)+"/v2/chat", bytes.NewReader(reqBody))
+	if err != nil {
+		return Result{}, fmt.Errorf("request creation failed: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	p.setAuthHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body, p.config.MaxResponseBytes)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError cohereError
+		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
+			return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: apiError.Message}
+		}
+		return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: errorBodyMessage(resp, body, p.config)}
+	}
+
+	var response struct {
+		ID      string `json:"id"`
+		Message struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+		Usage *struct {
+			Tokens struct {
+				InputTokens  float64 `json:"input_tokens"`
+				OutputTokens float64 `json:"output_tokens"`
+			} `json:"tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Result{}, fmt.Errorf("response parsing failed: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range response.Message.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return Result{}, ErrNoContent
+	}
+
+	result := Result{
+		Content:   text.String(),
+		RequestID: requestIDFromHeader(resp.Header),
+		Model:     p.getModel(),
+	}
+	if result.RequestID == "" {
+		result.RequestID = response.ID
+	}
+	if u := response.Usage; u != nil {
+		in, out := int(u.Tokens.InputTokens), int(u.Tokens.OutputTokens)
+		result.Usage = &Usage{PromptTokens: in, CompletionTokens: out, TotalTokens: in + out}
+	}
+	return result, nil
+}
+
+func (p *Cohere) setAuthHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	setCommonHeaders(req, p.config)
+}
+
+func (p *Cohere) getModel() string {
+	if p.config.Model != "" {
+		return p.config.Model
+	}
+	return cohereDefaultModel
+}
+
+type CohereModelsResponse struct {
+	Models []struct {
+		Name          string   `json:"name"`
+		Endpoints     []string `json:"endpoints"`
+		ContextLength float64  `json:"context_length"`
+		Features      []string `json:"features"`
+	} `json:"models"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// ListModels returns the models that serve the chat endpoint.
+func (p *Cohere) ListModels(ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	endpoint := baseURL(p.config, cohereBaseURL) + "/v1/models?endpoint=chat&page_size=1000"
+	err := fetchPagesBy(ctx, p.client, p.config, endpoint, "page_token", p.setAuthHeaders, func(body []byte) (string, error) {
+		var response CohereModelsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
+		for _, m := range response.Models {
+			vision := false
+			for _, f := range m.Features {
+				vision = vision || f == "vision"
+			}
+			models = append(models, Model{
+				ID:             m.Name,
+				Description:    "Cohere " + m.Name,
+				ContextWindow:  int(m.ContextLength),
+				SupportsVision: vision,
+			})
+		}
+		return response.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
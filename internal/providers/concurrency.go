@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// requestSlots bounds the HTTP requests in flight across every provider in
+// this process; nil means unlimited.
+var requestSlots chan struct{}
+
+// SetMaxConcurrentRequests caps the number of outstanding HTTP calls shared by
+// all providers, regardless of how many goroutines issue them. n <= 0 removes
+// the cap. Call it before creating providers.
+func SetMaxConcurrentRequests(n int) {
+	if n <= 0 {
+		requestSlots = nil
+		return
+	}
+	requestSlots = make(chan struct{}, n)
+}
+
+// limitTransport holds a slot from slots for the lifetime of each request,
+// from sending it until the response body is closed.
+type limitTransport struct {
+	base  http.RoundTripper
+	slots chan struct{}
+}
+
+func (t limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() { <-t.slots }}
+	return resp, nil
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// secretHeaders are masked in --dump-curl output unless DumpCurlUnsafe is set.
+var secretHeaders = map[string]bool{
+	"Authorization":        true,
+	"Api-Key":              true,
+	"X-Api-Key":            true,
+	"X-Amz-Security-Token": true,
+}
+
+// isSecretHeader reports whether key carries a credential: one of
+// secretHeaders or the --auth-header the API key is sent in.
+func isSecretHeader(key, authHeader string) bool {
+	return secretHeaders[key] || (authHeader != "" && key == http.CanonicalHeaderKey(authHeader))
+}
+
+// curlTransport prints an equivalent curl command for every request before
+// sending it unchanged.
+type curlTransport struct {
+	base       http.RoundTripper
+	w          io.Writer
+	unsafe     bool
+	authHeader string
+}
+
+func (t curlTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cmd, err := curlCommand(req, t.unsafe, t.authHeader)
+	if err != nil {
+		fmt.Fprintf(t.w, "# --dump-curl: %v\n", err)
+	} else {
+		fmt.Fprintln(t.w, cmd)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// curlCommand renders req as a shell-quoted curl invocation. A gzipped body
+// is shown decompressed (without Content-Encoding) so it stays readable.
+// Credentials, including authHeader, are masked unless unsafe is set.
+func curlCommand(req *http.Request, unsafe bool, authHeader string) (string, error) {
+	parts := []string{"curl", "-X", req.Method, shellQuote(req.URL.String())}
+
+	body, err := requestBody(req)
+	if err != nil {
+		return "", err
+	}
+	gzipped := req.Header.Get("Content-Encoding") == "gzip"
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if gzipped && key == "Content-Encoding" {
+			continue
+		}
+		for _, value := range req.Header[key] {
+			if isSecretHeader(key, authHeader) && !unsafe {
+				value = maskHeaderValue(value)
+			}
+			parts = append(parts, "-H", shellQuote(key+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		parts = append(parts, "--data-raw", shellQuote(string(body)))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// requestBody returns a copy of req's body, decompressed when it was sent
+// gzipped, leaving the request itself untouched.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return nil, nil
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Header.Get("Content-Encoding") == "gzip" && len(body) > 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(zr)
+	}
+	return body, nil
+}
+
+// maskHeaderValue hides a credential while keeping its scheme, so
+// "Bearer sk-abc" becomes "Bearer ****".
+func maskHeaderValue(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok {
+		return scheme + " ****"
+	}
+	return "****"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// A key sent in a custom --auth-header must be masked in --dump-curl output
+// and in --save-exchange records, like the built-in credential headers.
+func TestAuthHeaderMasked(t *testing.T) {
+	const key = "sk-gateway-secret"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Gateway-Key"); got != key {
+			t.Errorf("X-Gateway-Key = %q, want the key", got)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	recorder := &ExchangeRecorder{}
+	p := NewCustom(Config{
+		APIKey:     key,
+		AuthHeader: "x-gateway-key",
+		BaseURL:    srv.URL,
+		Model:      "test-model",
+		DumpCurl:   true,
+		Exchanges:  recorder,
+	})
+	_, genErr := p.Generate(context.Background(), Inputs{Prompt: "hi"})
+	os.Stderr = stderr
+	w.Close()
+	if genErr != nil {
+		t.Fatalf("Generate: %v", genErr)
+	}
+
+	curl, _ := io.ReadAll(r)
+	if !strings.Contains(string(curl), "X-Gateway-Key: ****") {
+		t.Errorf("curl output doesn't show the masked header:\n%s", curl)
+	}
+	if strings.Contains(string(curl), key) {
+		t.Errorf("curl output leaks the key:\n%s", curl)
+	}
+
+	saved, err := json.Marshal(recorder.Exchanges())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Exchanges()) != 1 {
+		t.Fatalf("recorded %d exchanges, want 1", len(recorder.Exchanges()))
+	}
+	if strings.Contains(string(saved), key) {
+		t.Errorf("saved exchange leaks the key:\n%s", saved)
+	}
+}
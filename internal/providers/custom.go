@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+=== Custom OpenAI-compatible endpoint ===
+Any server that implements OpenAI's /chat/completions, such as LiteLLM,
+vLLM, LocalAI or an in-house gateway. Config.BaseURL and Config.Model are
+required; there is no public endpoint or default model to fall back to.
+The API key is optional and sent as a bearer token unless
+Config.AuthHeader names another header.
+*/
+
+type Custom struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:       "custom",
+		APIKeyEnv:  "AI_CLI_CUSTOM_API_KEY",
+		SelfHosted: true,
+		BaseURLEnv: "AI_CLI_BASE_URL",
+		ModelEnv:   "AI_CLI_CUSTOM_MODEL",
+		New:        func(config Config) Provider { return NewCustom(config) },
+	})
+}
+
+func NewCustom(config Config) *Custom {
+	return &Custom{openai: newOpenAICompatible(config, openAIService{name: "custom", optionalAuth: true})}
+}
+
+// Supports claims every feature of the OpenAI protocol; what a given server
+// actually accepts is only known once it answers.
+func (p *Custom) Supports(feature Feature) bool {
+	return p.openai.Supports(feature)
+}
+
+func (p *Custom) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
+
+func (p *Custom) ListModels(ctx context.Context) ([]Model, error) {
+	return listOpenAICompatibleModels(ctx, p.openai, baseURL(p.openai.config, ""))
+}
+
+// listOpenAICompatibleModels returns everything a server's /models reports,
+// without the OpenAI chat-model filter: self-hosted servers name models
+// after their files.
+func listOpenAICompatibleModels(ctx context.Context, p *OpenAI, root string) ([]Model, error) {
+	models := []Model{}
+	err := fetchPages(ctx, p.client, p.config, root+"/models", p.setAuthHeaders, func(body []byte) (string, error) {
+		var response OpenAIModelResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
+		lastID := ""
+		for _, m := range response.Data {
+			lastID = m.ID
+			description := m.ID
+			if m.OwnedBy != "" {
+				description = fmt.Sprintf("%s (%s)", m.ID, m.OwnedBy)
+			}
+			models = append(models, Model{ID: m.ID, Description: description})
+		}
+		return response.next(lastID), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 )
@@ -28,17 +27,28 @@ type DeepSeek struct {
 	client *http.Client
 }
 
+// deepseekError covers both error bodies DeepSeek sends: OpenAI's nested
+// {"error": {...}} and a bare {"message": ...}.
 type deepseekError struct {
+	openAIError
 	Message string `json:"message"`
 }
 
+func init() {
+	Register(Registration{
+		Name:           "deepseek",
+		Default:        true,
+		APIKeyEnv:      "DEEPSEEK_API_KEY",
+		DefaultModel:   deepseekDefaultModel,
+		DefaultBaseURL: deepseekBaseURL,
+		New:            func(config Config) Provider { return NewDeepSeek(config) },
+	})
+}
+
 func NewDeepSeek(config Config) *DeepSeek {
-	if config.Timeout == 0 {
-		config.Timeout = int(deepseekDefaultTimeout.Seconds())
-	}
 	return &DeepSeek{
 		config: config,
-		client: &http.Client{Timeout: deepseekDefaultTimeout},
+		client: newHTTPClient(config, requestTimeout(config, deepseekDefaultTimeout)),
 	}
 }
 
@@ -46,55 +56,65 @@ func (p *DeepSeek) Supports(feature Feature) bool {
 	return feature == FeatureTextGeneration
 }
 
-func (p *DeepSeek) Generate(ctx context.Context, inputs Inputs) (string, error) {
+func (p *DeepSeek) Generate(ctx context.Context, inputs Inputs) (Result, error) {
 	if len(inputs.Images) > 0 {
-		return "", fmt.Errorf("DeepSeek does not support image analysis")
+		return Result{}, fmt.Errorf("DeepSeek does not support image analysis")
 	}
-	return p.handleTextRequest(ctx, inputs.Prompt)
+	return p.handleTextRequest(ctx, inputs.Prompt, inputs.Messages)
 }
 
-func (p *DeepSeek) handleTextRequest(ctx context.Context, prompt string) (string, error) {
+func (p *DeepSeek) handleTextRequest(ctx context.Context, prompt string, history []Message) (Result, error) {
 	payload := map[string]any{
-		"model": p.getModel(),
-		"messages": []map[string]any{
-			{"role": "user", "content": prompt},
-		},
-		"max_tokens": 1000,
+		"model":    p.getModel(),
+		"messages": chatMessages(p.config, history, prompt),
 	}
+	applySampling(p.config, payload)
+	applyExtraParams(p.config, payload)
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("marshal error: %w", err)
+		return Result{}, fmt.Errorf("marshal error: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", deepseekBaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	reqBody, contentEncoding := encodeRequestBody(p.config, jsonData)
+	logPayloadSize(p.config, "deepseek", jsonData, reqBody, 0)
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL(p.config, deepseekBaseURL)+"/chat/completions", bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("request creation failed: %w", err)
+		return Result{}, fmt.Errorf("request creation failed: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
 	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	setCommonHeaders(req, p.config)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return Result{}, fmt.Errorf("API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body, p.config.MaxResponseBytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return Result{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		var apiError deepseekError
-		if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
-			return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, apiError.Message)
+		if json.Unmarshal(body, &apiError) == nil && apiError.Error.Message != "" {
+			return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: apiError.Error.Message, Code: apiError.Error.Code, Type: apiError.Error.Type}
+		}
+		if apiError.Message != "" {
+			return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: apiError.Message}
 		}
-		return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, string(body))
+		return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: errorBodyMessage(resp, body, p.config)}
 	}
 
 	var response struct {
+		Model   string `json:"model"`
+		Usage   *Usage `json:"usage"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
@@ -103,14 +123,19 @@ func (p *DeepSeek) handleTextRequest(ctx context.Context, prompt string) (string
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("response parsing failed: %w", err)
+		return Result{}, fmt.Errorf("response parsing failed: %w", err)
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return Result{}, ErrNoContent
 	}
 
-	return response.Choices[0].Message.Content, nil
+	return Result{
+		Content:   response.Choices[0].Message.Content,
+		RequestID: requestIDFromHeader(resp.Header),
+		Model:     response.Model,
+		Usage:     response.Usage,
+	}, nil
 }
 
 func (p *DeepSeek) getModel() string {
@@ -128,41 +153,36 @@ type DeepSeekModelsResponse struct {
 			ContextWindow int    `json:"context_length"`
 		} `json:"capabilities"`
 	} `json:"data"`
+	listPage
 }
 
 func (p *DeepSeek) ListModels(ctx context.Context) ([]Model, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", deepseekBaseURL+"/models", nil)
-	if err != nil {
-		return nil, fmt.Errorf("request creation failed: %w", err)
+	authorize := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+		setCommonHeaders(req, p.config)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	models := []Model{}
+	err := fetchPages(ctx, p.client, p.config, baseURL(p.config, deepseekBaseURL)+"/models", authorize, func(body []byte) (string, error) {
+		var response DeepSeekModelsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
 
-	resp, err := p.client.Do(req)
+		lastID := ""
+		for _, m := range response.Data {
+			lastID = m.ID
+			models = append(models, Model{
+				ID:             m.ID,
+				Description:    m.Details.Description,
+				ContextWindow:  m.Details.ContextWindow,
+				SupportsVision: false, // DeepSeek currently has no vision models
+			})
+		}
+		return response.next(lastID), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error [%d]: %s", resp.StatusCode, string(body))
-	}
-
-	var response DeepSeekModelsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("response parsing failed: %w", err)
-	}
-
-	var models []Model
-	for _, m := range response.Data {
-		models = append(models, Model{
-			ID:             m.ID,
-			Description:    m.Details.Description,
-			ContextWindow:  m.Details.ContextWindow,
-			SupportsVision: false, // DeepSeek currently has no vision models
-		})
-	}
-
 	return models, nil
 }
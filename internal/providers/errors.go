@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrNoContent is returned when a provider answers 200 with no choices,
+// which happens on content filtering and the odd transient glitch.
+var ErrNoContent = errors.New("no content in response")
+
+// maxPlainErrorBytes is the longest non-JSON error body quoted verbatim;
+// anything longer, or any HTML, is summarized instead.
+const maxPlainErrorBytes = 200
+
+// errorBodyMessage turns an error response body the provider couldn't parse
+// into an APIError message. JSON and short plain-text bodies are kept as
+// they are; HTML pages and long bodies, typically from a proxy or gateway,
+// become "non-JSON response from <host> (text/html, 1.2KB)". The full body
+// is written to stderr with Config.Debug.
+func errorBodyMessage(resp *http.Response, body []byte, config Config) string {
+	trimmed := bytes.TrimSpace(body)
+	contentType := resp.Header.Get("Content-Type")
+	isHTML := strings.Contains(contentType, "html") || bytes.HasPrefix(trimmed, []byte("<"))
+	if json.Valid(trimmed) || (!isHTML && len(trimmed) <= maxPlainErrorBytes) {
+		return string(trimmed)
+	}
+
+	if config.Debug {
+		fmt.Fprintf(os.Stderr, "[DEBUG] HTTP %d error body:\n%s\n", resp.StatusCode, body)
+	}
+	host := "the server"
+	if resp.Request != nil && resp.Request.URL != nil {
+		host = resp.Request.URL.Host
+	}
+	kind, _, _ := strings.Cut(contentType, ";")
+	if kind == "" {
+		kind = "unknown content type"
+	}
+	return fmt.Sprintf("non-JSON response from %s (%s, %s)", host, strings.TrimSpace(kind), formatBytes(len(body)))
+}
+
+// formatBytes renders a size as 512B, 1.2KB or 3.4MB.
+func formatBytes(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	}
+}
+
+// APIError is returned when a provider answers with a non-200 status.
+type APIError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	Code       string // provider error code when given, e.g. "model_not_found"
+	Type       string // provider error type when given, e.g. "insufficient_quota"
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error [%d]", e.StatusCode)
+	}
+	return fmt.Sprintf("API error [%d]: %s", e.StatusCode, e.Message)
+}
+
+// IsAuth reports whether the provider rejected the credentials.
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsModelNotFound reports whether the requested model doesn't exist or was
+// retired. OpenAI sends a code; DeepSeek ("Model Not Exist") and Mistral
+// ("Invalid model: ...") only say so in the message.
+func (e *APIError) IsModelNotFound() bool {
+	if e.Code == "model_not_found" {
+		return true
+	}
+	if e.StatusCode != http.StatusBadRequest && e.StatusCode != http.StatusNotFound {
+		return false
+	}
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "invalid model") ||
+		(strings.Contains(msg, "model") && (strings.Contains(msg, "not found") || strings.Contains(msg, "not exist")))
+}
+
+// IsRateLimit reports whether the request was throttled.
+func (e *APIError) IsRateLimit() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// quotaErrors are the codes and types providers use when the account is out
+// of credit; they come with a 429 but waiting won't clear them.
+var quotaErrors = map[string]bool{
+	"insufficient_quota":         true,
+	"billing_hard_limit_reached": true,
+	"billing_not_active":         true,
+}
+
+// IsQuotaExceeded reports whether the account has run out of quota or has a
+// billing problem. DeepSeek answers 402 Payment Required when the balance is
+// used up.
+func (e *APIError) IsQuotaExceeded() bool {
+	return e.StatusCode == http.StatusPaymentRequired || quotaErrors[e.Code] || quotaErrors[e.Type]
+}
+
+// IsRetryable reports whether the request was throttled in a way that
+// clears by waiting, i.e. a rate limit that isn't a quota error.
+func (e *APIError) IsRetryable() bool {
+	return e.IsRateLimit() && !e.IsQuotaExceeded()
+}
@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exchange is one HTTP request and the response to it, with credentials
+// masked, for attaching exact reproductions to bug reports.
+type Exchange struct {
+	Request    ExchangeRequest   `json:"request"`
+	Response   *ExchangeResponse `json:"response,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	DurationMS int64             `json:"duration_ms"`
+}
+
+type ExchangeRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type ExchangeResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// ExchangeRecorder collects every exchange a provider makes, retries and
+// pagination included. Set it as Config.Exchanges.
+type ExchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// Exchanges returns the recorded exchanges in the order they were made.
+func (r *ExchangeRecorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+func (r *ExchangeRecorder) add(e Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, e)
+}
+
+// exchangeTransport records each request and response into a recorder.
+// The response body is read in full and handed on unchanged.
+type exchangeTransport struct {
+	base       http.RoundTripper
+	recorder   *ExchangeRecorder
+	authHeader string
+}
+
+func (t exchangeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := Exchange{Request: ExchangeRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: flattenHeaders(req.Header, true, t.authHeader),
+	}}
+	if body, err := requestBody(req); err == nil {
+		exchange.Request.Body = rawBody(body)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	exchange.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		exchange.Error = err.Error()
+		t.recorder.add(exchange)
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	exchange.Response = &ExchangeResponse{
+		Status:  resp.StatusCode,
+		Headers: flattenHeaders(resp.Header, false, ""),
+		Body:    rawBody(body),
+	}
+	if readErr != nil {
+		exchange.Error = readErr.Error()
+	}
+	t.recorder.add(exchange)
+	return resp, nil
+}
+
+// flattenHeaders joins repeated headers with ", ", masking credentials,
+// including authHeader, in request headers.
+func flattenHeaders(h http.Header, mask bool, authHeader string) map[string]string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	flat := make(map[string]string, len(h))
+	for _, key := range keys {
+		value := strings.Join(h[key], ", ")
+		if mask && isSecretHeader(key, authHeader) {
+			value = maskHeaderValue(value)
+		}
+		flat[key] = value
+	}
+	return flat
+}
+
+// rawBody keeps a JSON body as nested JSON and encodes anything else as a
+// JSON string, so the saved document stays readable.
+func rawBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
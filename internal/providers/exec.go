@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+/*
+=== Exec ===
+Runs a user-supplied command as the provider. The request is written to the
+command's stdin as JSON:
+
+	{"prompt": "...", "model": "...", "images": [{"filename": "a.png", "data": "<base64>"}]}
+
+system, temperature and max_tokens are added when they are configured, and
+messages holds earlier conversation turns as {"role", "content"} objects.
+Everything the command prints on stdout becomes the response. A non-zero
+exit status is reported as an error together with the command's stderr. The
+API key, when one is given, is passed in the AI_CLI_API_KEY environment
+variable rather than on the command line.
+*/
+
+type Exec struct {
+	config Config
+}
+
+type execImage struct {
+	Filename string `json:"filename"`
+	Data     []byte `json:"data"`
+}
+
+type execRequest struct {
+	Prompt      string      `json:"prompt"`
+	System      string      `json:"system,omitempty"`
+	Model       string      `json:"model,omitempty"`
+	Temperature *float64    `json:"temperature,omitempty"`
+	MaxTokens   int         `json:"max_tokens,omitempty"`
+	Messages    []Message   `json:"messages,omitempty"`
+	Images      []execImage `json:"images,omitempty"`
+}
+
+func init() {
+	Register(Registration{
+		Name: "exec",
+		New:  func(config Config) Provider { return NewExec(config) },
+	})
+}
+
+func NewExec(config Config) *Exec {
+	return &Exec{config: config}
+}
+
+// Supports returns true for every feature the exec protocol can carry; the
+// command decides what it can actually handle. Its plain-text output has no
+// room for log probabilities, and the request has no suffix or schema.
+func (p *Exec) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureLogProbs, FeatureFIM, FeatureStructuredOutput:
+		return false
+	}
+	return true
+}
+
+// mergeExtraParams adds Config.ExtraParams to the encoded exec request.
+func mergeExtraParams(config Config, payload []byte) ([]byte, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	applyExtraParams(config, fields)
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	return merged, nil
+}
+
+func (p *Exec) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	args := strings.Fields(p.config.Command)
+	if len(args) == 0 {
+		return Result{}, fmt.Errorf("exec provider: no command configured")
+	}
+
+	request := execRequest{
+		Prompt:      inputs.Prompt,
+		System:      p.config.SystemPrompt,
+		Model:       p.config.Model,
+		Temperature: p.config.Temperature,
+		MaxTokens:   p.config.MaxTokens,
+		Messages:    inputs.Messages,
+	}
+	for _, img := range inputs.Images {
+		request.Images = append(request.Images, execImage{Filename: img.Filename, Data: img.Data})
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal error: %w", err)
+	}
+	if len(p.config.ExtraParams) > 0 {
+		payload, err = mergeExtraParams(p.config, payload)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	logPayloadSize(p.config, "exec", payload, payload, len(request.Images))
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	if p.config.APIKey != "" {
+		cmd.Env = append(os.Environ(), "AI_CLI_API_KEY="+p.config.APIKey)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if p.config.Debug {
+		fmt.Fprintf(os.Stderr, "[DEBUG] Running exec provider: %s\n", p.config.Command)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Result{}, fmt.Errorf("exec provider %s failed: %w: %s", args[0], err, msg)
+		}
+		return Result{}, fmt.Errorf("exec provider %s failed: %w", args[0], err)
+	}
+
+	return Result{Content: strings.TrimSuffix(stdout.String(), "\n")}, nil
+}
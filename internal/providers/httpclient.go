@@ -0,0 +1,206 @@
+package providers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// newHTTPClient builds the client a provider uses for its API calls, applying
+// the TLS options, diagnostics and the process-wide concurrency cap. Without any
+// of them it uses the default transport, exactly as before. Config.HTTPClient,
+// when set, is used as is instead.
+func newHTTPClient(config Config, timeout time.Duration) *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if config.CACertFile != "" || config.InsecureSkipVerify {
+		tlsConfig, err := buildTLSConfig(config)
+		if err != nil {
+			// Constructors can't fail, so report the problem on the first request.
+			client.Transport = errorTransport{err: err}
+			return client
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	if config.DumpCurl {
+		client.Transport = curlTransport{base: transportOrDefault(client.Transport), w: os.Stderr, unsafe: config.DumpCurlUnsafe, authHeader: config.AuthHeader}
+	}
+	if config.Exchanges != nil {
+		client.Transport = exchangeTransport{base: transportOrDefault(client.Transport), recorder: config.Exchanges, authHeader: config.AuthHeader}
+	}
+	if config.Trace {
+		client.Transport = traceTransport{base: transportOrDefault(client.Transport), w: os.Stderr}
+	}
+	if requestSlots != nil {
+		client.Transport = limitTransport{base: transportOrDefault(client.Transport), slots: requestSlots}
+	}
+	return client
+}
+
+func transportOrDefault(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// DefaultMaxResponseBytes caps how much of a response body is read when
+// Config.MaxResponseBytes is zero.
+const DefaultMaxResponseBytes = 32 << 20
+
+// readResponseBody reads at most limit bytes (DefaultMaxResponseBytes when
+// zero) so a misbehaving endpoint can't exhaust memory.
+func readResponseBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response body exceeds the %d byte limit", limit)
+	}
+	return data, nil
+}
+
+// requestIDHeaders are the response headers providers use for their
+// correlation ID, in order of preference.
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "Mistral-Correlation-Id", "X-Ds-Trace-Id", "X-Amzn-Requestid"}
+
+func requestIDFromHeader(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// setCommonHeaders applies the headers every provider sends. Call it after
+// the provider's own headers so Config.Headers can override them.
+func setCommonHeaders(req *http.Request, config Config) {
+	if config.RequestID != "" {
+		req.Header.Set("X-Request-ID", config.RequestID)
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// applyExtraParams shallow-merges Config.ExtraParams into a request
+// payload, replacing fields the provider set itself with a warning.
+func applyExtraParams(config Config, payload map[string]any) {
+	for key, value := range config.ExtraParams {
+		if _, ok := payload[key]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: extra params override request field %q\n", key)
+		}
+		payload[key] = value
+	}
+}
+
+// compressMinBytes is the smallest request body Config.Compress will gzip.
+// Measured on JSON chat payloads: ~330 bytes only shrinks to 79%, 1 KiB to
+// 49%, 4 KiB to 40% and 16 KiB+ to under 20%. Base64 images are already
+// compressed and barely change (~99%), so the gain is all in long text.
+const compressMinBytes = 1 << 10
+
+// encodeRequestBody gzips data when config.Compress is set and the body is
+// large enough to benefit, returning the Content-Encoding to send ("" for
+// none).
+func encodeRequestBody(config Config, data []byte) ([]byte, string) {
+	if !config.Compress || len(data) < compressMinBytes {
+		return data, ""
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return data, ""
+	}
+	if err := zw.Close(); err != nil {
+		return data, ""
+	}
+	return buf.Bytes(), "gzip"
+}
+
+// logPayloadSize reports the marshaled request size in debug mode, and the
+// size on the wire when it was compressed. Only sizes are logged, never the
+// body, so base64 image data stays out of the output.
+func logPayloadSize(config Config, provider string, payload, sent []byte, images int) {
+	if !config.Debug {
+		return
+	}
+	if len(sent) != len(payload) {
+		fmt.Fprintf(os.Stderr, "[DEBUG] %s: request payload %d bytes (%d gzipped), images=%d\n", provider, len(payload), len(sent), images)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[DEBUG] %s: request payload %d bytes, images=%d\n", provider, len(payload), images)
+}
+
+// baseURL returns Config.BaseURL without a trailing slash, or the
+// provider's own endpoint when it is empty.
+func baseURL(config Config, defaultURL string) string {
+	if config.BaseURL != "" {
+		return strings.TrimRight(config.BaseURL, "/")
+	}
+	return defaultURL
+}
+
+// requestTimeout returns config.Timeout (in seconds) when set, otherwise the
+// provider's default. There is deliberately no upper bound: slow reasoning
+// models can legitimately take minutes.
+func requestTimeout(config Config, defaultTimeout time.Duration) time.Duration {
+	if config.Timeout > 0 {
+		return time.Duration(config.Timeout) * time.Second
+	}
+	return defaultTimeout
+}
+
+func buildTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		// Dangerous: only meant for gateways with self-signed certificates.
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CACertFile != "" {
+		pem, err := os.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", config.CACertFile, err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle %s", config.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	const def = 30 * time.Second
+	tests := []struct {
+		name    string
+		timeout int
+		want    time.Duration
+	}{
+		{"unset uses the default", 0, def},
+		{"positive overrides the default", 90, 90 * time.Second},
+		{"longer than the default is allowed", 600, 10 * time.Minute},
+		{"one second", 1, time.Second},
+		{"negative falls back to the default", -5, def},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := requestTimeout(Config{Timeout: tc.timeout}, def); got != tc.want {
+				t.Errorf("requestTimeout(%d) = %s, want %s", tc.timeout, got, tc.want)
+			}
+		})
+	}
+}
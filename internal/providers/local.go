@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+/*
+=== Local servers (LM Studio, llama.cpp) ===
+LM Studio's server and llama.cpp's llama-server expose OpenAI's chat API on
+localhost without authentication. Without --model the model currently
+loaded is picked from /v1/models. Vision needs a multimodal model (LM Studio
+VLMs, or llama-server started with --mmproj).
+
+- lmstudio: http://localhost:1234/v1
+- llamacpp: http://localhost:8080/v1 (key from --api-key, if set, via LLAMA_API_KEY)
+*/
+
+const (
+	lmStudioBaseURL = "http://localhost:1234/v1"
+	llamaCppBaseURL = "http://localhost:8080/v1"
+
+	// localDefaultTimeout allows for slow CPU inference and for LM Studio
+	// loading the model on the first request.
+	localDefaultTimeout = 300
+)
+
+type Local struct {
+	openai *OpenAI
+
+	mu         sync.Mutex
+	discovered bool
+}
+
+func init() {
+	Register(Registration{
+		Name:           "lmstudio",
+		SelfHosted:     true,
+		DefaultBaseURL: lmStudioBaseURL,
+		New:            func(config Config) Provider { return NewLMStudio(config) },
+	})
+	Register(Registration{
+		Name:           "llamacpp",
+		APIKeyEnv:      "LLAMA_API_KEY",
+		SelfHosted:     true,
+		DefaultBaseURL: llamaCppBaseURL,
+		New:            func(config Config) Provider { return NewLlamaCpp(config) },
+	})
+}
+
+// NewLMStudio talks to LM Studio's local server.
+func NewLMStudio(config Config) *Local {
+	return newLocal(config, openAIService{name: "lmstudio", baseURL: lmStudioBaseURL, optionalAuth: true})
+}
+
+// NewLlamaCpp talks to llama.cpp's llama-server.
+func NewLlamaCpp(config Config) *Local {
+	return newLocal(config, openAIService{name: "llamacpp", baseURL: llamaCppBaseURL, optionalAuth: true})
+}
+
+func newLocal(config Config, service openAIService) *Local {
+	if config.Timeout == 0 {
+		config.Timeout = localDefaultTimeout
+	}
+	return &Local{openai: newOpenAICompatible(config, service)}
+}
+
+func (p *Local) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureTextGeneration, FeatureVision, FeatureStructuredOutput:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Local) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	if err := p.discoverModel(ctx); err != nil {
+		return Result{}, err
+	}
+	return p.openai.Generate(ctx, inputs)
+}
+
+// discoverModel fills in the loaded model when none was configured. A
+// failure isn't remembered, so a server started later is still found.
+func (p *Local) discoverModel(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered || p.openai.config.Model != "" {
+		return nil
+	}
+
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("finding the loaded model at %s (is the server running?): %w", p.baseURL(), err)
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no model is loaded at %s; load one or pass --model", p.baseURL())
+	}
+	p.openai.config.Model = models[0].ID
+	p.discovered = true
+	return nil
+}
+
+func (p *Local) ListModels(ctx context.Context) ([]Model, error) {
+	return listOpenAICompatibleModels(ctx, p.openai, p.baseURL())
+}
+
+func (p *Local) baseURL() string {
+	return baseURL(p.openai.config, p.openai.service.baseURL)
+}
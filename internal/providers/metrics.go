@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the histogram upper bounds in seconds.
+var defaultLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type requestKey struct {
+	provider string
+	status   string
+}
+
+type latencyHistogram struct {
+	counts []uint64 // one per bucket, non-cumulative
+	sum    float64
+	count  uint64
+}
+
+// Metrics collects request counts and latencies per provider. It is safe for
+// concurrent use and renders a snapshot in the Prometheus text format.
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestKey]uint64
+	latencies map[string]*latencyHistogram
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:   defaultLatencyBuckets,
+		requests:  make(map[requestKey]uint64),
+		latencies: make(map[string]*latencyHistogram),
+	}
+}
+
+// Observe records one request against provider.
+func (m *Metrics) Observe(provider string, elapsed time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{provider: provider, status: status}]++
+
+	h, ok := m.latencies[provider]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[provider] = h
+	}
+	seconds := elapsed.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// WritePrometheus writes the current snapshot in the Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].provider != keys[j].provider {
+			return keys[i].provider < keys[j].provider
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "# HELP ai_cli_requests_total Total number of generate requests.")
+	fmt.Fprintln(&b, "# TYPE ai_cli_requests_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "ai_cli_requests_total{provider=%q,status=%q} %d\n", k.provider, k.status, m.requests[k])
+	}
+
+	names := make([]string, 0, len(m.latencies))
+	for name := range m.latencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(&b, "# HELP ai_cli_request_duration_seconds Latency of generate requests.")
+	fmt.Fprintln(&b, "# TYPE ai_cli_request_duration_seconds histogram")
+	for _, name := range names {
+		h := m.latencies[name]
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "ai_cli_request_duration_seconds_bucket{provider=%q,le=\"%g\"} %d\n", name, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "ai_cli_request_duration_seconds_bucket{provider=%q,le=\"+Inf\"} %d\n", name, h.count)
+		fmt.Fprintf(&b, "ai_cli_request_duration_seconds_sum{provider=%q} %g\n", name, h.sum)
+		fmt.Fprintf(&b, "ai_cli_request_duration_seconds_count{provider=%q} %d\n", name, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WithMetrics records every Generate call for provider into m.
+func WithMetrics(m *Metrics, provider string) ProviderMiddleware {
+	return func(next Provider) Provider {
+		return &generateFunc{
+			Provider: next,
+			generate: func(ctx context.Context, inputs Inputs) (Result, error) {
+				start := time.Now()
+				result, err := next.Generate(ctx, inputs)
+				m.Observe(provider, time.Since(start), err)
+				return result, err
+			},
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// generateFunc lets a middleware replace Generate while the embedded
+// Provider keeps answering Supports.
+type generateFunc struct {
+	Provider
+	generate func(ctx context.Context, inputs Inputs) (Result, error)
+}
+
+func (g *generateFunc) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return g.generate(ctx, inputs)
+}
+
+// WithLogging writes a debug line before and after each Generate call.
+func WithLogging(name string, w io.Writer) ProviderMiddleware {
+	return func(next Provider) Provider {
+		return &generateFunc{
+			Provider: next,
+			generate: func(ctx context.Context, inputs Inputs) (Result, error) {
+				fmt.Fprintf(w, "[DEBUG] %s: generate started (prompt=%d chars, images=%d)\n",
+					name, len(inputs.Prompt), len(inputs.Images))
+
+				result, err := next.Generate(ctx, inputs)
+				if err != nil {
+					var apiErr *APIError
+					if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+						fmt.Fprintf(w, "[DEBUG] %s: generate failed (request_id=%s): %v\n", name, apiErr.RequestID, err)
+					} else {
+						fmt.Fprintf(w, "[DEBUG] %s: generate failed: %v\n", name, err)
+					}
+					return result, err
+				}
+
+				fmt.Fprintf(w, "[DEBUG] %s: generate succeeded (%d chars, request_id=%s)\n",
+					name, len(result.Content), result.RequestID)
+				return result, nil
+			},
+		}
+	}
+}
+
+// WithTiming measures each Generate call and reports the latency to fn.
+func WithTiming(fn func(elapsed time.Duration, err error)) ProviderMiddleware {
+	return func(next Provider) Provider {
+		return &generateFunc{
+			Provider: next,
+			generate: func(ctx context.Context, inputs Inputs) (Result, error) {
+				start := time.Now()
+				result, err := next.Generate(ctx, inputs)
+				fn(time.Since(start), err)
+				return result, err
+			},
+		}
+	}
+}
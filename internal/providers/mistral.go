@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -20,6 +19,7 @@ import (
 const (
 	mistralBaseURL        = "https://api.mistral.ai/v1"
 	mistralDefaultModel   = "mistral-small-latest"
+	mistralFIMModel       = "codestral-latest"
 	mistralDefaultTimeout = 30 * time.Second
 	mistralMaxRetries     = 2
 	mistralRetryDelay     = 1 * time.Second
@@ -32,57 +32,98 @@ type Mistral struct {
 
 type mistralError struct {
 	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func init() {
+	Register(Registration{
+		Name:           "mistral",
+		Default:        true,
+		APIKeyEnv:      "MISTRAL_API_KEY",
+		DefaultModel:   mistralDefaultModel,
+		DefaultBaseURL: mistralBaseURL,
+		New:            func(config Config) Provider { return NewMistral(config) },
+	})
 }
 
 func NewMistral(config Config) *Mistral {
-	timeout := mistralDefaultTimeout
-	if config.Timeout > 0 && config.Timeout <= 30 {
-		timeout = time.Duration(config.Timeout) * time.Second
-	}
 	return &Mistral{
 		config: config,
-		client: &http.Client{Timeout: timeout},
+		client: newHTTPClient(config, requestTimeout(config, mistralDefaultTimeout)),
 	}
 }
 
 func (p *Mistral) Supports(feature Feature) bool {
-	return feature == FeatureTextGeneration
+	return feature == FeatureTextGeneration || feature == FeatureFIM
 }
 
-func (p *Mistral) Generate(ctx context.Context, inputs Inputs) (string, error) {
+func (p *Mistral) Generate(ctx context.Context, inputs Inputs) (Result, error) {
 	if len(inputs.Images) > 0 {
-		return "", fmt.Errorf("Mistral does not support image analysis")
+		return Result{}, fmt.Errorf("Mistral does not support image analysis")
 	}
-	return p.handleTextRequest(ctx, inputs.Prompt)
+	if inputs.Suffix != "" {
+		return p.handleFIMRequest(ctx, inputs.Prompt, inputs.Suffix)
+	}
+	return p.handleTextRequest(ctx, inputs.Prompt, inputs.Messages)
 }
 
-func (p *Mistral) handleTextRequest(ctx context.Context, prompt string) (string, error) {
+func (p *Mistral) handleTextRequest(ctx context.Context, prompt string, history []Message) (Result, error) {
+	payload := map[string]interface{}{
+		"model":    p.getModel(),
+		"messages": chatMessages(p.config, history, prompt),
+	}
+	return p.complete(ctx, "/chat/completions", payload)
+}
+
+// handleFIMRequest asks a code model to fill the gap between prompt and
+// suffix. Without --model it uses Codestral, since chat models don't serve
+// the FIM endpoint.
+func (p *Mistral) handleFIMRequest(ctx context.Context, prompt, suffix string) (Result, error) {
+	model := p.config.Model
+	if model == "" {
+		model = mistralFIMModel
+	}
 	payload := map[string]interface{}{
-		"model":      p.getModel(),
-		"messages":   []map[string]interface{}{{"role": "user", "content": prompt}},
-		"max_tokens": 1000,
+		"model":  model,
+		"prompt": prompt,
+		"suffix": suffix,
 	}
+	return p.complete(ctx, "/fim/completions", payload)
+}
 
+// complete posts payload to a completions endpoint, retrying network errors
+// and rate limits, and returns the first choice. Chat and FIM responses
+// share this shape.
+func (p *Mistral) complete(ctx context.Context, path string, payload map[string]interface{}) (Result, error) {
+	applySampling(p.config, payload)
+	applyExtraParams(p.config, payload)
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("marshal error: %w", err)
+		return Result{}, fmt.Errorf("marshal error: %w", err)
 	}
 
+	reqBody, contentEncoding := encodeRequestBody(p.config, jsonData)
+	logPayloadSize(p.config, "mistral", jsonData, reqBody, 0)
+
 	var lastErr error
 	for attempt := 1; attempt <= mistralMaxRetries; attempt++ {
 		start := time.Now()
-		req, err := http.NewRequestWithContext(ctx, "POST", mistralBaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "POST", baseURL(p.config, mistralBaseURL)+path, bytes.NewReader(reqBody))
 		if err != nil {
-			return "", fmt.Errorf("request creation failed: %w", err)
+			return Result{}, fmt.Errorf("request creation failed: %w", err)
 		}
 
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
 		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+		setCommonHeaders(req, p.config)
 
 		if p.config.Debug {
 			fmt.Printf("[DEBUG] Attempt %d: Sending request to Mistral: URL=%s, Model=%s, APIKey=%s\n",
-				attempt, mistralBaseURL+"/chat/completions", p.getModel(), maskAPIKey(p.config.APIKey))
+				attempt, baseURL(p.config, mistralBaseURL)+path, payload["model"], maskAPIKey(p.config.APIKey))
 		}
 
 		resp, err := p.client.Do(req)
@@ -92,16 +133,27 @@ func (p *Mistral) handleTextRequest(ctx context.Context, prompt string) (string,
 				fmt.Printf("[DEBUG] Attempt %d failed after %s: %v\n", attempt, time.Since(start), err)
 			}
 			if attempt < mistralMaxRetries {
-				time.Sleep(mistralRetryDelay)
+				if !takeRetry() {
+					if p.config.Debug {
+						fmt.Printf("[DEBUG] Retry budget exhausted, not retrying\n")
+					}
+					return Result{}, lastErr
+				}
+				select {
+				case <-ctx.Done():
+					return Result{}, lastErr
+				case <-time.After(mistralRetryDelay):
+				}
 				continue
 			}
-			return "", lastErr
+			return Result{}, lastErr
 		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
+		// Close now rather than deferring: a retry must not wait for this
+		// attempt's concurrency slot, which is held until the body closes.
+		body, err := readResponseBody(resp.Body, p.config.MaxResponseBytes)
+		resp.Body.Close()
 		if err != nil {
-			return "", fmt.Errorf("failed to read response body: %w", err)
+			return Result{}, fmt.Errorf("failed to read response body: %w", err)
 		}
 
 		if p.config.Debug {
@@ -110,14 +162,27 @@ func (p *Mistral) handleTextRequest(ctx context.Context, prompt string) (string,
 		}
 
 		if resp.StatusCode != http.StatusOK {
+			apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: errorBodyMessage(resp, body, p.config)}
 			var apiError mistralError
 			if json.Unmarshal(body, &apiError) == nil && apiError.Message != "" {
-				return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, apiError.Message)
+				apiErr.Message, apiErr.Type = apiError.Message, apiError.Type
+			}
+			// Rate limits clear by waiting; quota and other errors won't.
+			if !apiErr.IsRetryable() || attempt == mistralMaxRetries || !takeRetry() {
+				return Result{}, apiErr
 			}
-			return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, string(body))
+			lastErr = apiErr
+			select {
+			case <-ctx.Done():
+				return Result{}, lastErr
+			case <-time.After(mistralRetryDelay):
+			}
+			continue
 		}
 
 		var response struct {
+			Model   string `json:"model"`
+			Usage   *Usage `json:"usage"`
 			Choices []struct {
 				Message struct {
 					Content string `json:"content"`
@@ -126,68 +191,64 @@ func (p *Mistral) handleTextRequest(ctx context.Context, prompt string) (string,
 		}
 
 		if err := json.Unmarshal(body, &response); err != nil {
-			return "", fmt.Errorf("response parsing failed: %w", err)
+			return Result{}, fmt.Errorf("response parsing failed: %w", err)
 		}
 
 		if len(response.Choices) == 0 {
-			return "", fmt.Errorf("no content in response")
+			return Result{}, ErrNoContent
 		}
 
 		if p.config.Debug {
 			fmt.Printf("[DEBUG] Success after %s\n", time.Since(start))
 		}
-		return response.Choices[0].Message.Content, nil
+		return Result{
+			Content:   response.Choices[0].Message.Content,
+			RequestID: requestIDFromHeader(resp.Header),
+			Model:     response.Model,
+			Usage:     response.Usage,
+		}, nil
 	}
 
-	return "", lastErr
+	return Result{}, lastErr
 }
 
 func (p *Mistral) ListModels(ctx context.Context) ([]Model, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", mistralBaseURL+"/models", nil)
-	if err != nil {
-		return nil, fmt.Errorf("request creation failed: %w", err)
+	authorize := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+		setCommonHeaders(req, p.config)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	models := []Model{}
+	err := fetchPages(ctx, p.client, p.config, baseURL(p.config, mistralBaseURL)+"/models", authorize, func(body []byte) (string, error) {
+		var response struct {
+			Data []struct {
+				ID      string `json:"id"`
+				Created int64  `json:"created"`
+				Object  string `json:"object"`
+				OwnedBy string `json:"owned_by"`
+			} `json:"data"`
+			listPage
+		}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
 
-	body, err := io.ReadAll(resp.Body)
+		lastID := ""
+		for _, m := range response.Data {
+			lastID = m.ID
+			models = append(models, Model{
+				ID:             m.ID,
+				Description:    fmt.Sprintf("Mistral model: %s", m.ID),
+				ContextWindow:  getMistralContextWindow(m.ID),
+				SupportsVision: false,
+			})
+		}
+		return response.next(lastID), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error [%d]: %s", resp.StatusCode, string(body))
-	}
-
-	var response struct {
-		Data []struct {
-			ID      string `json:"id"`
-			Created int64  `json:"created"`
-			Object  string `json:"object"`
-			OwnedBy string `json:"owned_by"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("response parsing failed: %w", err)
-	}
-
-	models := make([]Model, 0, len(response.Data))
-	for _, m := range response.Data {
-		models = append(models, Model{
-			ID:             m.ID,
-			Description:    fmt.Sprintf("Mistral model: %s", m.ID),
-			ContextWindow:  getMistralContextWindow(m.ID),
-			SupportsVision: false,
-		})
-	}
-
 	return models, nil
 }
 
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// A rate-limited attempt must release its concurrency slot before the
+// retry, or a limit of one request deadlocks until the client timeout.
+func TestMistralRetryReleasesConcurrencySlot(t *testing.T) {
+	SetMaxConcurrentRequests(1)
+	defer SetMaxConcurrentRequests(0)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Requests rate limit exceeded","type":"rate_limited"}`))
+			return
+		}
+		w.Write([]byte(`{"model":"mistral-small-latest","choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewMistral(Config{APIKey: "test-key", BaseURL: srv.URL, Timeout: 5})
+	result, err := p.Generate(context.Background(), Inputs{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("Generate: %v (after %d calls)", err, calls.Load())
+	}
+	if result.Content != "ok" || calls.Load() != 2 {
+		t.Errorf("content = %q after %d calls, want \"ok\" after 2", result.Content, calls.Load())
+	}
+}
@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"strings"
+)
+
+/*
+=== Mock ===
+Answers without network access or credentials, for CI, tutorials and offline
+demos. The response is Config.MockResponse when set, otherwise the prompt is
+echoed back. It claims every feature so any flag combination can be
+exercised; log probabilities, when requested, are a zero per word, and usage
+is estimated from the text length.
+*/
+
+const mockModel = "mock"
+
+type Mock struct {
+	config Config
+}
+
+func init() {
+	Register(Registration{
+		Name:         "mock",
+		DefaultModel: mockModel,
+		New:          func(config Config) Provider { return NewMock(config) },
+	})
+}
+
+func NewMock(config Config) *Mock {
+	return &Mock{config: config}
+}
+
+func (p *Mock) Supports(feature Feature) bool {
+	return true
+}
+
+func (p *Mock) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	content := p.config.MockResponse
+	if content == "" {
+		content = inputs.Prompt
+	}
+
+	result := Result{
+		Content:   content,
+		RequestID: p.config.RequestID,
+		Model:     p.getModel(),
+		Usage: &Usage{
+			PromptTokens:     EstimateTokens(inputs.Prompt),
+			CompletionTokens: EstimateTokens(content),
+		},
+	}
+	result.Usage.TotalTokens = result.Usage.PromptTokens + result.Usage.CompletionTokens
+	if p.config.LogProbs || p.config.TopLogProbs > 0 {
+		for _, word := range strings.Fields(content) {
+			result.LogProbs = append(result.LogProbs, TokenLogProb{Token: word})
+		}
+	}
+	return result, nil
+}
+
+func (p *Mock) ListModels(ctx context.Context) ([]Model, error) {
+	return []Model{{
+		ID:             p.getModel(),
+		Description:    "Offline mock model",
+		ContextWindow:  128000,
+		SupportsVision: true,
+	}}, nil
+}
+
+func (p *Mock) getModel() string {
+	if p.config.Model != "" {
+		return p.config.Model
+	}
+	return mockModel
+}
@@ -6,9 +6,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -28,101 +28,185 @@ Vision Limitations:
 - Max image size: 20MB (PNG/JPEG/WEBP/non-animated GIF)
 - Medical images not supported
 - Struggles with rotated text, spatial reasoning, and non-Latin characters
-- Image costs: 85-170 tokens per 512px tile + base 85 tokens
+- Image costs: 85-170 tokens per 512px tile + base 85 tokens (see EstimateVisionTokens)
 */
 
 const (
-	openAIBaseURL          = "https://api.openai.com/v1"
-	openAIDefaultTimeout   = 30 * time.Second
-	openAIDefaultTextModel = "gpt-4"
-	openAIVisionModel      = "gpt-4o-mini" //models supporting images as input: o1, gpt-4.5-preview, gpt-4o, gpt-4o-mini, gpt-4-turbo
+	openAIBaseURL             = "https://api.openai.com/v1"
+	openAIDefaultTimeout      = 30 * time.Second
+	openAIDefaultTextModel    = "gpt-4"
+	openAIVisionModel         = "gpt-4o-mini" //models supporting images as input: o1, gpt-4.5-preview, gpt-4o, gpt-4o-mini, gpt-4-turbo
+	openAIDefaultVisionPrompt = "Describe the image(s)."
 )
 
 type OpenAI struct {
-	config Config
-	client *http.Client
+	config  Config
+	client  *http.Client
+	service openAIService
+
+	// azure switches to Azure OpenAI deployment URLs and api-key auth; see
+	// AzureOpenAI.
+	azure bool
+}
+
+// openAIService describes an API that speaks OpenAI's chat completions
+// protocol. Providers such as OpenRouter reuse the OpenAI implementation
+// with their own service.
+type openAIService struct {
+	name         string // provider name, for debug logs
+	baseURL      string
+	defaultModel string
+	// visionModel serves every request with images; empty uses the
+	// configured model, or defaultVisionModel when none is configured.
+	visionModel        string
+	defaultVisionModel string
+	// optionalAuth sends no credentials when there is no API key, for
+	// self-hosted servers, and honours Config.AuthHeader.
+	optionalAuth bool
+}
+
+var openAIPlatform = openAIService{
+	name:         "openai",
+	baseURL:      openAIBaseURL,
+	defaultModel: openAIDefaultTextModel,
+	visionModel:  openAIVisionModel,
 }
 
 type openAIError struct {
 	Error struct {
 		Message string `json:"message"`
+		Code    string `json:"code"`
+		Type    string `json:"type"`
 	} `json:"error"`
 }
 
+func init() {
+	Register(Registration{
+		Name:               "openai",
+		Default:            true,
+		APIKeyEnv:          "OPENAI_API_KEY",
+		DefaultModel:       openAIDefaultTextModel,
+		DefaultVisionModel: openAIVisionModel,
+		DefaultBaseURL:     openAIBaseURL,
+		New:                func(config Config) Provider { return NewOpenAI(config) },
+	})
+}
+
 func NewOpenAI(config Config) *OpenAI {
-	if config.Timeout == 0 {
-		config.Timeout = int(openAIDefaultTimeout.Seconds())
-	}
+	return newOpenAICompatible(config, openAIPlatform)
+}
+
+func newOpenAICompatible(config Config, service openAIService) *OpenAI {
 	return &OpenAI{
-		config: config,
-		client: &http.Client{Timeout: openAIDefaultTimeout},
+		config:  config,
+		client:  newHTTPClient(config, requestTimeout(config, openAIDefaultTimeout)),
+		service: service,
 	}
 }
 
 func (p *OpenAI) Supports(feature Feature) bool {
 	switch feature {
-	case FeatureTextGeneration, FeatureVision, FeatureMultiModal:
+	case FeatureTextGeneration, FeatureVision, FeatureMultiModal, FeatureLogProbs, FeatureStructuredOutput:
 		return true
 	default:
 		return false
 	}
 }
 
-func (p *OpenAI) Generate(ctx context.Context, inputs Inputs) (string, error) {
+func (p *OpenAI) Generate(ctx context.Context, inputs Inputs) (Result, error) {
 	if len(inputs.Images) > 0 {
 		return p.handleVisionRequest(ctx, inputs)
 	}
-	return p.handleTextRequest(ctx, inputs.Prompt)
+	return p.handleTextRequest(ctx, inputs.Prompt, inputs.Messages)
 }
 
-func (p *OpenAI) handleTextRequest(ctx context.Context, prompt string) (string, error) {
+func (p *OpenAI) handleTextRequest(ctx context.Context, prompt string, history []Message) (Result, error) {
 	payload := map[string]any{
-		"model": p.getModel(),
-		"messages": []map[string]any{
-			{"role": "user", "content": prompt},
-		},
-		"max_tokens": 1000,
+		"model":    p.getModel(),
+		"messages": chatMessages(p.config, history, prompt),
 	}
+	p.applyOptions(payload)
 
-	return p.makeRequest(ctx, payload, "/chat/completions")
+	return p.makeRequest(ctx, payload, "/chat/completions", 0)
 }
 
-func (p *OpenAI) handleVisionRequest(ctx context.Context, inputs Inputs) (string, error) {
+func (p *OpenAI) handleVisionRequest(ctx context.Context, inputs Inputs) (Result, error) {
+	prompt := inputs.Prompt
+	if strings.TrimSpace(prompt) == "" {
+		prompt = openAIDefaultVisionPrompt
+	}
+
 	content := []any{
-		map[string]string{"type": "text", "text": inputs.Prompt},
+		map[string]string{"type": "text", "text": prompt},
 	}
 
 	for _, img := range inputs.Images {
 		// Use the pre-loaded image data
 		base64Image := base64.StdEncoding.EncodeToString(img.Data)
 
+		imageURL := map[string]string{
+			"url": fmt.Sprintf("data:image/%s;base64,%s",
+				getMimeType(img.Filename),
+				base64Image,
+			),
+		}
+		if inputs.ImageDetail != "" {
+			imageURL["detail"] = inputs.ImageDetail
+		}
+
 		content = append(content, map[string]any{
-			"type": "image_url",
-			"image_url": map[string]string{
-				"url": fmt.Sprintf("data:image/%s;base64,%s",
-					getMimeType(img.Filename),
-					base64Image,
-				),
-			},
+			"type":      "image_url",
+			"image_url": imageURL,
 		})
 	}
 
+	model := p.service.visionModel
+	if model == "" && p.config.Model == "" {
+		model = p.service.defaultVisionModel
+	}
+	if model == "" {
+		model = p.getModel()
+	}
 	payload := map[string]any{
-		"model": openAIVisionModel,
-		"messages": []map[string]any{
-			{"role": "user", "content": content},
-		},
-		"max_tokens": 1000,
+		"model":    model,
+		"messages": chatMessages(p.config, inputs.Messages, content),
 	}
+	p.applyOptions(payload)
+
+	return p.makeRequest(ctx, payload, "/chat/completions", len(inputs.Images))
+}
 
-	return p.makeRequest(ctx, payload, "/chat/completions")
+// applyOptions adds the optional request fields shared by text and vision
+// requests.
+func (p *OpenAI) applyOptions(payload map[string]any) {
+	applySampling(p.config, payload)
+	if p.config.User != "" {
+		payload["user"] = p.config.User
+	}
+	if p.config.LogProbs || p.config.TopLogProbs > 0 {
+		payload["logprobs"] = true
+		if p.config.TopLogProbs > 0 {
+			payload["top_logprobs"] = p.config.TopLogProbs
+		}
+	}
+	if rs := p.config.ResponseSchema; rs != nil {
+		payload["response_format"] = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   rs.Name,
+				"schema": rs.Schema,
+				"strict": true,
+			},
+		}
+	}
+	applyExtraParams(p.config, payload)
 }
 
 func (p *OpenAI) getModel() string {
 	if p.config.Model != "" {
 		return p.config.Model
 	}
-	return openAIDefaultTextModel
+	return p.service.defaultModel
 }
 
 func getMimeType(filename string) string {
@@ -134,66 +218,158 @@ func getMimeType(filename string) string {
 		return "jpeg"
 	case ".gif":
 		return "gif"
+	case ".webp":
+		return "webp"
 	default:
 		return "jpeg"
 	}
 }
 
-func (p *OpenAI) makeRequest(ctx context.Context, payload any, endpoint string) (string, error) {
+func (p *OpenAI) makeRequest(ctx context.Context, payload any, endpoint string, images int) (Result, error) {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("marshal error: %w", err)
+		return Result{}, fmt.Errorf("marshal error: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", openAIBaseURL+endpoint, bytes.NewBuffer(jsonData))
+	reqBody, contentEncoding := encodeRequestBody(p.config, jsonData)
+	logPayloadSize(p.config, p.service.name, jsonData, reqBody, images)
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpointURL(endpoint), bytes.NewReader(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("request creation failed: %w", err)
+		return Result{}, fmt.Errorf("request creation failed: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	p.setAuthHeaders(req)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return Result{}, fmt.Errorf("API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body, p.config.MaxResponseBytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return Result{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		var apiError openAIError
 		if json.Unmarshal(body, &apiError) == nil && apiError.Error.Message != "" {
-			return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, apiError.Error.Message)
+			return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: apiError.Error.Message, Code: apiError.Error.Code, Type: apiError.Error.Type}
 		}
-		return "", fmt.Errorf("API error [%d]: %s", resp.StatusCode, string(body))
+		return Result{}, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: errorBodyMessage(resp, body, p.config)}
 	}
 
 	var response struct {
+		Model string `json:"model"`
+		Usage *Usage `json:"usage"`
+		// Perplexity lists its sources in citations, or in the newer
+		// search_results.
+		Citations     []string `json:"citations"`
+		SearchResults []struct {
+			URL string `json:"url"`
+		} `json:"search_results"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
+				Refusal string `json:"refusal"`
 			} `json:"message"`
+			LogProbs *struct {
+				Content []struct {
+					Token       string  `json:"token"`
+					LogProb     float64 `json:"logprob"`
+					TopLogProbs []struct {
+						Token   string  `json:"token"`
+						LogProb float64 `json:"logprob"`
+					} `json:"top_logprobs"`
+				} `json:"content"`
+			} `json:"logprobs"`
 		} `json:"choices"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("response parsing failed: %w", err)
+		return Result{}, fmt.Errorf("response parsing failed: %w", err)
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return Result{}, ErrNoContent
+	}
+	// Structured outputs report a refusal instead of non-conforming content.
+	if msg := response.Choices[0].Message; msg.Content == "" && msg.Refusal != "" {
+		return Result{}, fmt.Errorf("model refused the request: %s", msg.Refusal)
+	}
+
+	result := Result{
+		Content:   response.Choices[0].Message.Content,
+		RequestID: requestIDFromHeader(resp.Header),
+		Model:     response.Model,
+		Usage:     response.Usage,
+		Citations: response.Citations,
+	}
+	if len(result.Citations) == 0 {
+		for _, sr := range response.SearchResults {
+			result.Citations = append(result.Citations, sr.URL)
+		}
 	}
+	if lp := response.Choices[0].LogProbs; lp != nil {
+		for _, t := range lp.Content {
+			token := TokenLogProb{Token: t.Token, LogProb: t.LogProb}
+			for _, alt := range t.TopLogProbs {
+				token.TopLogProbs = append(token.TopLogProbs, TokenLogProb{Token: alt.Token, LogProb: alt.LogProb})
+			}
+			result.LogProbs = append(result.LogProbs, token)
+		}
+	}
+	return result, nil
+}
 
-	return response.Choices[0].Message.Content, nil
+// endpointURL returns the URL of an API endpoint such as /chat/completions.
+func (p *OpenAI) endpointURL(endpoint string) string {
+	if p.azure {
+		return azureURL(p.config, p.getModel(), endpoint)
+	}
+	return baseURL(p.config, p.service.baseURL) + endpoint
+}
+
+func (p *OpenAI) setAuthHeaders(req *http.Request) {
+	if p.azure {
+		req.Header.Set("api-key", p.config.APIKey)
+		setCommonHeaders(req, p.config)
+		return
+	}
+	if p.service.optionalAuth {
+		if p.config.APIKey != "" {
+			if p.config.AuthHeader != "" {
+				req.Header.Set(p.config.AuthHeader, p.config.APIKey)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+			}
+		}
+		setCommonHeaders(req, p.config)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	if p.service.name != openAIPlatform.name {
+		// Organization and project are OpenAI account settings.
+		setCommonHeaders(req, p.config)
+		return
+	}
+	if p.config.Organization != "" {
+		req.Header.Set("OpenAI-Organization", p.config.Organization)
+	}
+	if p.config.Project != "" {
+		req.Header.Set("OpenAI-Project", p.config.Project)
+	}
+	setCommonHeaders(req, p.config)
 }
 
 type OpenAIModelResponse struct {
 	Object string        `json:"object"`
 	Data   []OpenAIModel `json:"data"`
+	listPage
 }
 
 type OpenAIModel struct {
@@ -205,48 +381,67 @@ type OpenAIModel struct {
 }
 
 func (p *OpenAI) ListModels(ctx context.Context) ([]Model, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", openAIBaseURL+"/models", nil)
-	if err != nil {
-		return nil, fmt.Errorf("request creation failed: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API request failed: %w", err)
-	}
-	defer resp.Body.Close()
+	models := []Model{}
+	err := fetchPages(ctx, p.client, p.config, baseURL(p.config, openAIBaseURL)+"/models", p.setAuthHeaders, func(body []byte) (string, error) {
+		var response OpenAIModelResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
 
-	body, err := io.ReadAll(resp.Body)
+		lastID := ""
+		for _, m := range response.Data {
+			lastID = m.ID
+			if !p.config.IncludeAllModels && !isChatModel(m.ID) {
+				continue
+			}
+			models = append(models, Model{
+				ID:             m.ID,
+				Description:    fmt.Sprintf("%s (%s)", m.ID, m.OwnedBy),
+				ContextWindow:  getOpenAIContextWindow(m.ID),
+				SupportsVision: isVisionModel(m.ID),
+			})
+		}
+		return response.next(lastID), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error [%d]", resp.StatusCode)
-	}
-
-	var response OpenAIModelResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("response parsing failed: %w", err)
-	}
-
-	models := make([]Model, 0, len(response.Data))
-	for _, m := range response.Data {
-		models = append(models, Model{
-			ID:             m.ID,
-			Description:    fmt.Sprintf("%s (%s)", m.ID, m.OwnedBy),
-			ContextWindow:  getOpenAIContextWindow(m.ID),
-			SupportsVision: isVisionModel(m.ID),
-		})
+		return nil, err
 	}
-
 	return models, nil
 }
 
+// openAIContextWindows maps model ID prefixes to context sizes. More specific
+// prefixes must come before the shorter ones they start with.
+var openAIContextWindows = []struct {
+	prefix string
+	window int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4.5", 128000},
+	{"gpt-4o", 128000},
+	{"chatgpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-1106", 128000},
+	{"gpt-4-0125", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo-instruct", 4096},
+	{"gpt-3.5-turbo", 16385},
+	{"o1-mini", 128000},
+	{"o1-preview", 128000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4-mini", 200000},
+}
+
 // Helper functions
 func getOpenAIContextWindow(modelID string) int {
+	for _, entry := range openAIContextWindows {
+		if strings.HasPrefix(modelID, entry.prefix) {
+			return entry.window
+		}
+	}
+
+	// Last resort for unknown IDs that spell out their size.
 	switch {
 	case strings.Contains(modelID, "128k"):
 		return 128000
@@ -259,8 +454,64 @@ func getOpenAIContextWindow(modelID string) int {
 	}
 }
 
+// nonChatModelMarkers identify /models entries that can't serve chat
+// completions.
+var nonChatModelMarkers = []string{
+	"embedding", "whisper", "tts", "dall-e", "moderation",
+	"davinci", "babbage", "transcribe", "audio", "realtime", "search",
+	"image", "instruct",
+}
+
+// datedSnapshot matches pinned snapshots such as gpt-4-0613 or
+// gpt-4o-2024-08-06; their undated alias is listed separately.
+var datedSnapshot = regexp.MustCompile(`-(\d{4}|\d{4}-\d{2}-\d{2})(-preview)?$`)
+
+// isChatModel reports whether modelID is a current chat-capable model worth
+// showing by default.
+func isChatModel(modelID string) bool {
+	id := strings.ToLower(modelID)
+	if strings.HasPrefix(id, "ft:") {
+		return false
+	}
+	for _, marker := range nonChatModelMarkers {
+		if strings.Contains(id, marker) {
+			return false
+		}
+	}
+	if datedSnapshot.MatchString(id) {
+		return false
+	}
+	for _, prefix := range []string{"gpt-", "chatgpt-", "o1", "o3", "o4"} {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// visionModels lists the undated IDs of models that accept image input.
+// Audio, realtime and search variants of gpt-4o are text/audio only, as are
+// gpt-4-turbo-preview, o1-mini and o3-mini.
+var visionModels = map[string]bool{
+	"gpt-4o":                    true,
+	"gpt-4o-mini":               true,
+	"chatgpt-4o-latest":         true,
+	"gpt-4-turbo":               true,
+	"gpt-4-vision-preview":      true,
+	"gpt-4-1106-vision-preview": true,
+	"gpt-4.1":                   true,
+	"gpt-4.1-mini":              true,
+	"gpt-4.1-nano":              true,
+	"gpt-4.5-preview":           true,
+	"o1":                        true,
+	"o3":                        true,
+	"o4-mini":                   true,
+}
+
+// isVisionModel reports whether modelID accepts images. Dated snapshots
+// (gpt-4o-2024-08-06, gpt-4-turbo-2024-04-09) inherit the capability of
+// their undated alias; anything else must be listed in visionModels.
 func isVisionModel(modelID string) bool {
-	return strings.Contains(modelID, "vision") ||
-		strings.Contains(modelID, "gpt-4o") ||
-		strings.Contains(modelID, "turbo-vision")
+	id := datedSnapshot.ReplaceAllString(strings.ToLower(modelID), "$2")
+	return visionModels[id]
 }
@@ -0,0 +1,85 @@
+package providers
+
+import "testing"
+
+func TestOpenAIContextWindow(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-4o", 128000},
+		{"gpt-4o-2024-08-06", 128000},
+		{"gpt-4.1-mini", 1047576},
+		{"gpt-4-turbo", 128000},
+		{"gpt-4-32k", 32768},
+		{"gpt-4", 8192},
+		{"gpt-4-0613", 8192},
+		{"gpt-3.5-turbo-instruct", 4096},
+		{"gpt-3.5-turbo", 16385},
+		{"o1-mini", 128000},
+		{"o1", 200000},
+		{"some-model-32k", 32000},
+		{"unknown-model", 4096},
+	}
+	for _, tc := range tests {
+		if got := getOpenAIContextWindow(tc.model); got != tc.want {
+			t.Errorf("getOpenAIContextWindow(%q) = %d, want %d", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestIsVisionModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"gpt-4o", true},
+		{"gpt-4o-2024-08-06", true},
+		{"GPT-4o-Mini-2024-07-18", true},
+		{"gpt-4-turbo-2024-04-09", true},
+		{"gpt-4-turbo-preview", false},
+		{"gpt-4-0125-preview", false},
+		{"gpt-4-1106-vision-preview", true},
+		{"gpt-4o-audio-preview", false},
+		{"gpt-4o-audio-preview-2024-12-17", false},
+		{"o3-mini", false},
+		{"gpt-4.5-preview", true},
+		{"gpt-4", false},
+	}
+	for _, tc := range tests {
+		if got := isVisionModel(tc.model); got != tc.want {
+			t.Errorf("isVisionModel(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestIsChatModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"gpt-4o", true},
+		{"gpt-4.1-mini", true},
+		{"chatgpt-4o-latest", true},
+		{"o3-mini", true},
+		{"gpt-4o-2024-08-06", false},
+		{"gpt-image-1", false},
+		{"gpt-3.5-turbo-instruct", false},
+		{"gpt-3.5-turbo-instruct-0914", false},
+		{"text-embedding-3-small", false},
+		{"whisper-1", false},
+		{"tts-1-hd", false},
+		{"dall-e-3", false},
+		{"gpt-4o-audio-preview", false},
+		{"gpt-4o-realtime-preview", false},
+		{"gpt-4o-mini-search-preview", false},
+		{"omni-moderation-latest", false},
+		{"davinci-002", false},
+		{"ft:gpt-4o-mini:acme::abc123", false},
+	}
+	for _, tc := range tests {
+		if got := isChatModel(tc.model); got != tc.want {
+			t.Errorf("isChatModel(%q) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+}
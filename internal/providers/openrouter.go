@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+/*
+=== OpenRouter ===
+One API key for hundreds of models from many vendors, behind an
+OpenAI-compatible chat endpoint. Model IDs carry the vendor:
+openai/gpt-4o-mini, anthropic/claude-3.5-sonnet, meta-llama/llama-3.1-70b-instruct.
+
+The optional HTTP-Referer and X-Title headers attribute requests to an app
+on openrouter.ai; set them through Config.Headers.
+*/
+
+const (
+	openRouterBaseURL      = "https://openrouter.ai/api/v1"
+	openRouterDefaultModel = "openai/gpt-4o-mini"
+)
+
+type OpenRouter struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:           "openrouter",
+		APIKeyEnv:      "OPENROUTER_API_KEY",
+		DefaultModel:   openRouterDefaultModel,
+		DefaultBaseURL: openRouterBaseURL,
+		New:            func(config Config) Provider { return NewOpenRouter(config) },
+	})
+}
+
+func NewOpenRouter(config Config) *OpenRouter {
+	return &OpenRouter{openai: newOpenAICompatible(config, openAIService{
+		name:         "openrouter",
+		baseURL:      openRouterBaseURL,
+		defaultModel: openRouterDefaultModel,
+	})}
+}
+
+// Supports reports vision as available; OpenRouter rejects images sent to
+// models that can't read them.
+func (p *OpenRouter) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration || feature == FeatureVision
+}
+
+func (p *OpenRouter) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
+
+type OpenRouterModelsResponse struct {
+	Data []struct {
+		ID            string `json:"id"`
+		Name          string `json:"name"`
+		ContextLength int    `json:"context_length"`
+		Architecture  struct {
+			InputModalities []string `json:"input_modalities"`
+		} `json:"architecture"`
+		// Prices are USD per token, as strings.
+		Pricing struct {
+			Prompt     string `json:"prompt"`
+			Completion string `json:"completion"`
+		} `json:"pricing"`
+	} `json:"data"`
+}
+
+// ListModels reads OpenRouter's catalog, which is public and unpaginated.
+func (p *OpenRouter) ListModels(ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	err := fetchPages(ctx, p.openai.client, p.openai.config, baseURL(p.openai.config, openRouterBaseURL)+"/models", p.openai.setAuthHeaders, func(body []byte) (string, error) {
+		var response OpenRouterModelsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
+		for _, m := range response.Data {
+			model := Model{
+				ID:            m.ID,
+				Description:   m.Name,
+				ContextWindow: m.ContextLength,
+			}
+			for _, modality := range m.Architecture.InputModalities {
+				model.SupportsVision = model.SupportsVision || modality == "image"
+			}
+			input, inErr := strconv.ParseFloat(m.Pricing.Prompt, 64)
+			output, outErr := strconv.ParseFloat(m.Pricing.Completion, 64)
+			if inErr == nil && outErr == nil && input >= 0 && output >= 0 {
+				model.Pricing = &ModelPricing{Input: input * 1e6, Output: output * 1e6}
+				model.Description += fmt.Sprintf(" ($%.2f/$%.2f per M tokens)", model.Pricing.Input, model.Pricing.Output)
+			}
+			models = append(models, model)
+		}
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
@@ -0,0 +1,100 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxModelPages stops ListModels from following cursors forever when an
+// endpoint keeps reporting has_more.
+const maxModelPages = 50
+
+// listPage is the pagination envelope of OpenAI-style list endpoints. The
+// cursor for the next page is last_id, or the ID of the last item when the
+// provider only sends has_more.
+type listPage struct {
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// next returns the cursor for the following page, or "" on the last one.
+func (p listPage) next(lastItemID string) string {
+	if !p.HasMore {
+		return ""
+	}
+	if p.LastID != "" {
+		return p.LastID
+	}
+	return lastItemID
+}
+
+// fetchPages GETs endpoint and then every following page, passing each
+// body to parse, which returns the next cursor or "" when done. authorize
+// sets the provider's credentials on each request.
+func fetchPages(ctx context.Context, client *http.Client, config Config, endpoint string, authorize func(*http.Request), parse func(body []byte) (string, error)) error {
+	return fetchPagesBy(ctx, client, config, endpoint, "after", authorize, parse)
+}
+
+// fetchPagesBy is fetchPages for endpoints that take the cursor in a query
+// parameter other than "after".
+func fetchPagesBy(ctx context.Context, client *http.Client, config Config, endpoint, cursorParam string, authorize func(*http.Request), parse func(body []byte) (string, error)) error {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 1; ; page++ {
+		if page > maxModelPages {
+			return fmt.Errorf("model list still incomplete after %d pages", maxModelPages)
+		}
+
+		pageURL := endpoint
+		if cursor != "" {
+			pageURL += separator + cursorParam + "=" + url.QueryEscape(cursor)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			return fmt.Errorf("request creation failed: %w", err)
+		}
+		authorize(req)
+
+		body, err := func() ([]byte, error) {
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, fmt.Errorf("API request failed: %w", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := readResponseBody(resp.Body, config.MaxResponseBytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return nil, &APIError{StatusCode: resp.StatusCode, RequestID: requestIDFromHeader(resp.Header), Message: errorBodyMessage(resp, body, config)}
+			}
+			return body, nil
+		}()
+		if err != nil {
+			return err
+		}
+
+		next, err := parse(body)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		// A cursor that comes round again would loop until the page cap.
+		if seen[next] {
+			return fmt.Errorf("model list pagination repeated cursor %q", next)
+		}
+		seen[next] = true
+		cursor = next
+	}
+}
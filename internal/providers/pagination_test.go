@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func modelIDs(models []Model) string {
+	ids := make([]string, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestListModelsOpenAIPagination(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages map[string]string // "after" cursor -> body
+	}{
+		{
+			name: "last_id",
+			pages: map[string]string{
+				"":         `{"data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"}],"has_more":true,"last_id":"cursor-1"}`,
+				"cursor-1": `{"data":[{"id":"o3-mini"}],"has_more":false}`,
+			},
+		},
+		{
+			name: "has_more only",
+			pages: map[string]string{
+				"":            `{"data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"}],"has_more":true}`,
+				"gpt-4o-mini": `{"data":[{"id":"o3-mini"}]}`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				after := r.URL.Query().Get("after")
+				requests = append(requests, after)
+				if r.URL.Path != "/models" {
+					t.Errorf("path = %s, want /models", r.URL.Path)
+				}
+				body, ok := tc.pages[after]
+				if !ok {
+					http.Error(w, "unexpected cursor", http.StatusBadRequest)
+					return
+				}
+				fmt.Fprint(w, body)
+			}))
+			defer srv.Close()
+
+			models, err := NewOpenAI(Config{APIKey: "test-key", BaseURL: srv.URL}).ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels: %v", err)
+			}
+			if got := modelIDs(models); got != "gpt-4o,gpt-4o-mini,o3-mini" {
+				t.Errorf("models = %s", got)
+			}
+			if len(requests) != 2 {
+				t.Errorf("requests = %q, want 2 pages", requests)
+			}
+		})
+	}
+}
+
+func TestListModelsCursorPagination(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("endpoint") != "chat" {
+			t.Errorf("query = %s, lost the endpoint's own parameters", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("page_token") {
+		case "":
+			fmt.Fprint(w, `{"models":[{"name":"command-r"}],"next_page_token":"tok-2"}`)
+		case "tok-2":
+			fmt.Fprint(w, `{"models":[{"name":"command-a"}],"next_page_token":""}`)
+		default:
+			http.Error(w, "unexpected cursor", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	models, err := NewCohere(Config{APIKey: "test-key", BaseURL: srv.URL}).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if got := modelIDs(models); got != "command-r,command-a" {
+		t.Errorf("models = %s", got)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestFetchPagesRepeatedCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"id":"gpt-4o"}],"has_more":true,"last_id":"same"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAI(Config{APIKey: "test-key", BaseURL: srv.URL}).ListModels(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "repeated cursor") {
+		t.Errorf("err = %v, want repeated cursor error", err)
+	}
+}
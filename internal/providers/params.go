@@ -0,0 +1,35 @@
+package providers
+
+// DefaultMaxTokens is the completion cap sent when Config.MaxTokens is zero.
+const DefaultMaxTokens = 1000
+
+// maxTokens returns the completion cap for a request.
+func maxTokens(config Config) int {
+	if config.MaxTokens > 0 {
+		return config.MaxTokens
+	}
+	return DefaultMaxTokens
+}
+
+// chatMessages builds the messages array for a chat completion:
+// Config.SystemPrompt when set, then the earlier turns, then the user's
+// content.
+func chatMessages(config Config, history []Message, content any) []map[string]any {
+	var messages []map[string]any
+	if config.SystemPrompt != "" {
+		messages = append(messages, map[string]any{"role": "system", "content": config.SystemPrompt})
+	}
+	for _, msg := range history {
+		messages = append(messages, map[string]any{"role": msg.Role, "content": msg.Content})
+	}
+	return append(messages, map[string]any{"role": "user", "content": content})
+}
+
+// applySampling sets the completion cap and, when configured, the
+// temperature on a request payload.
+func applySampling(config Config, payload map[string]any) {
+	payload["max_tokens"] = maxTokens(config)
+	if config.Temperature != nil {
+		payload["temperature"] = *config.Temperature
+	}
+}
@@ -0,0 +1,50 @@
+package providers
+
+import "context"
+
+/*
+=== Perplexity ===
+Sonar models search the web and answer with [n] markers that refer to the
+returned citations (Result.Citations). OpenAI-compatible chat API; there is
+no model listing endpoint.
+
+- sonar: default, fast grounded answers (127K context)
+- sonar-pro: more searches and longer answers (200K context)
+- sonar-reasoning-pro: step-by-step reasoning with search (127K context)
+- sonar-deep-research: multi-step research reports (127K context)
+*/
+
+const (
+	perplexityBaseURL      = "https://api.perplexity.ai"
+	perplexityDefaultModel = "sonar"
+)
+
+type Perplexity struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:           "perplexity",
+		APIKeyEnv:      "PERPLEXITY_API_KEY",
+		DefaultModel:   perplexityDefaultModel,
+		DefaultBaseURL: perplexityBaseURL,
+		New:            func(config Config) Provider { return NewPerplexity(config) },
+	})
+}
+
+func NewPerplexity(config Config) *Perplexity {
+	return &Perplexity{openai: newOpenAICompatible(config, openAIService{
+		name:         "perplexity",
+		baseURL:      perplexityBaseURL,
+		defaultModel: perplexityDefaultModel,
+	})}
+}
+
+func (p *Perplexity) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration
+}
+
+func (p *Perplexity) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
@@ -0,0 +1,59 @@
+package providers
+
+import "strings"
+
+// modelPrices are list prices in USD per million input and output tokens,
+// matched by model ID prefix. More specific prefixes must come before the
+// shorter ones they start with. Prices change; treat estimates as rough.
+var modelPrices = []struct {
+	prefix        string
+	input, output float64
+}{
+	{"gpt-4.1-nano", 0.10, 0.40},
+	{"gpt-4.1-mini", 0.40, 1.60},
+	{"gpt-4.1", 2.00, 8.00},
+	{"gpt-4o-mini", 0.15, 0.60},
+	{"gpt-4o", 2.50, 10.00},
+	{"gpt-4-turbo", 10.00, 30.00},
+	{"gpt-4", 30.00, 60.00},
+	{"gpt-3.5-turbo", 0.50, 1.50},
+	{"o1-mini", 1.10, 4.40},
+	{"o1", 15.00, 60.00},
+	{"o3-mini", 1.10, 4.40},
+	{"o3", 2.00, 8.00},
+	{"o4-mini", 1.10, 4.40},
+	{"deepseek-chat", 0.27, 1.10},
+	{"deepseek-reasoner", 0.55, 2.19},
+	{"mistral-large", 2.00, 6.00},
+	{"mistral-medium", 0.40, 2.00},
+	{"mistral-small", 0.10, 0.30},
+	{"codestral", 0.30, 0.90},
+	{"pixtral-large", 2.00, 6.00},
+	{"open-mistral-nemo", 0.15, 0.15},
+	{"ministral-8b", 0.10, 0.10},
+	{"ministral-3b", 0.04, 0.04},
+	{"grok-4", 3.00, 15.00},
+	{"grok-3-mini", 0.30, 0.50},
+	{"grok-3", 3.00, 15.00},
+	{"grok-2-vision", 2.00, 10.00},
+	{"command-r-plus", 2.50, 10.00},
+	{"command-r7b", 0.0375, 0.15},
+	{"command-r", 0.15, 0.60},
+	{"command-a", 2.50, 10.00},
+}
+
+// EstimateCost returns the price in USD of a request to model with the
+// given token counts. ok is false when the model's price is unknown.
+// Router IDs such as openai/gpt-4o-mini are priced as the model after the
+// vendor prefix.
+func EstimateCost(model string, inputTokens, outputTokens int) (usd float64, ok bool) {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	for _, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			return (float64(inputTokens)*p.input + float64(outputTokens)*p.output) / 1e6, true
+		}
+	}
+	return 0, false
+}
@@ -2,10 +2,12 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 )
 
 type Provider interface {
-	Generate(ctx context.Context, inputs Inputs) (string, error)
+	Generate(ctx context.Context, inputs Inputs) (Result, error)
 	Supports(feature Feature) bool
 }
 
@@ -15,6 +17,9 @@ const (
 	FeatureTextGeneration Feature = iota
 	FeatureVision
 	FeatureMultiModal
+	FeatureLogProbs
+	FeatureFIM              // fill-in-the-middle completion from Inputs.Suffix
+	FeatureStructuredOutput // responses constrained by Config.ResponseSchema
 )
 
 type FileInput struct {
@@ -25,13 +30,169 @@ type FileInput struct {
 type Inputs struct {
 	Prompt string
 	Images []FileInput
+
+	// ImageDetail trades fidelity for cost on vision requests: "low",
+	// "high" or "auto". Empty leaves the provider default.
+	ImageDetail string
+
+	// Suffix switches to fill-in-the-middle: Prompt is the code before the
+	// gap and Suffix the code after it.
+	Suffix string
+
+	// Messages are earlier conversation turns, such as few-shot examples,
+	// sent ahead of Prompt.
+	Messages []Message
+}
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    string `json:"role"` // "system", "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// Result is a provider's answer together with response metadata.
+type Result struct {
+	Content   string
+	RequestID string // provider correlation ID, for support tickets
+	Model     string // model that served the request, as reported by the provider
+	LogProbs  []TokenLogProb
+	Usage     *Usage   // nil when the provider doesn't report token counts
+	Citations []string // source URLs of web-grounded answers, in [n] order
+}
+
+// Usage is the token accounting a provider reports for one request.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens" yaml:"prompt_tokens" xml:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens" yaml:"completion_tokens" xml:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens" yaml:"total_tokens" xml:"total_tokens"`
+}
+
+// TokenLogProb is the log probability of one generated token, with the most
+// likely alternatives when Config.TopLogProbs is set.
+type TokenLogProb struct {
+	Token       string         `json:"token" yaml:"token" xml:"token"`
+	LogProb     float64        `json:"logprob" yaml:"logprob" xml:"logprob"`
+	TopLogProbs []TokenLogProb `json:"top_logprobs,omitempty" yaml:"top_logprobs,omitempty" xml:"top_logprob,omitempty"`
 }
 
 type Config struct {
 	APIKey  string
-	Timeout int
+	Timeout int // seconds; zero uses the provider default (30s)
 	Model   string
 	Debug   bool // Added debug flag
+
+	// OpenAI billing attribution, sent as OpenAI-Organization/OpenAI-Project.
+	Organization string
+	Project      string
+
+	// CACertFile adds a PEM bundle to the trusted roots, e.g. for a gateway
+	// signed by a private CA.
+	CACertFile string
+	// InsecureSkipVerify disables TLS certificate verification. Dangerous:
+	// it exposes requests and API keys to interception. Never the default.
+	InsecureSkipVerify bool
+
+	// IncludeAllModels disables ListModels filtering (OpenAI hides embeddings,
+	// audio, image and dated snapshot models by default).
+	IncludeAllModels bool
+
+	// MaxResponseBytes caps the size of a response body; zero means
+	// DefaultMaxResponseBytes.
+	MaxResponseBytes int64
+
+	// RequestID is sent as X-Request-ID so calls can be correlated with
+	// gateway and provider logs.
+	RequestID string
+
+	// Exchanges, when set, records every HTTP request and response with
+	// credentials masked.
+	Exchanges *ExchangeRecorder
+
+	// HTTPClient, when set, is used for every request instead of the client
+	// built from the TLS, timeout and diagnostics options, e.g. to plug in
+	// an instrumented transport or an httptest server's client.
+	HTTPClient *http.Client
+
+	// BaseURL replaces the provider's API root, e.g. for a gateway or a
+	// local test server. Empty uses the provider's public endpoint. For
+	// Azure OpenAI it is the resource endpoint and is required.
+	BaseURL string
+
+	// AuthHeader names the header that carries APIKey, sent as is, for
+	// self-hosted servers that don't use "Authorization: Bearer". Only the
+	// custom, lmstudio, llamacpp and vllm providers read it.
+	AuthHeader string
+
+	// APIVersion is the Azure OpenAI api-version query parameter; empty
+	// uses azureDefaultAPIVersion.
+	APIVersion string
+
+	// VLLM holds vLLM's own sampling and guided decoding parameters.
+	VLLM VLLMOptions
+
+	// BedrockAPI picks the Bedrock API: BedrockConverse (the default when
+	// empty) or BedrockInvoke.
+	BedrockAPI string
+
+	// AWS configures region and credentials for Bedrock, which signs
+	// requests instead of sending APIKey.
+	AWS AWSConfig
+
+	// Headers are extra headers sent on every request, e.g. for gateways and
+	// observability proxies. They are applied last, so they can override the
+	// provider's own headers.
+	Headers map[string]string
+
+	// LogProbs asks for per-token log probabilities, and TopLogProbs for that
+	// many alternatives per token (0-20). Only OpenAI supports them.
+	LogProbs    bool
+	TopLogProbs int
+
+	// User is a stable end-user identifier for abuse monitoring. Only sent
+	// to providers that accept it (OpenAI's "user" field).
+	User string
+
+	// Compress gzips large request bodies (Content-Encoding: gzip). Only
+	// useful with endpoints that accept compressed requests.
+	Compress bool
+
+	// Trace logs DNS, connect, TLS and connection reuse for every HTTP
+	// request to stderr.
+	Trace bool
+
+	// DumpCurl prints an equivalent curl command for every HTTP request to
+	// stderr, with credentials masked unless DumpCurlUnsafe is set.
+	DumpCurl       bool
+	DumpCurlUnsafe bool
+
+	// Command is the program the exec provider runs, split on whitespace.
+	Command string
+
+	// MockResponse is what the mock provider answers; empty echoes the
+	// prompt.
+	MockResponse string
+
+	// ExtraParams are merged into the top level of every request payload,
+	// overriding fields of the same name, so new API parameters can be used
+	// before they get a flag.
+	ExtraParams map[string]any
+
+	// ResponseSchema constrains the response to a JSON Schema using strict
+	// structured outputs. Only OpenAI supports it.
+	ResponseSchema *ResponseSchema
+
+	// SystemPrompt is sent as a system message before the prompt.
+	SystemPrompt string
+	// Temperature is sent when non-nil; nil leaves the provider default.
+	Temperature *float64
+	// MaxTokens caps the completion; zero means DefaultMaxTokens.
+	MaxTokens int
+}
+
+// ResponseSchema is a named JSON Schema for structured outputs.
+type ResponseSchema struct {
+	Name   string // [a-zA-Z0-9_-], at most 64 characters
+	Schema json.RawMessage
 }
 
 type ModelLister interface {
@@ -39,8 +200,29 @@ type ModelLister interface {
 }
 
 type Model struct {
-	ID             string `json:"id"`
-	Description    string `json:"description"`
-	ContextWindow  int    `json:"context_window"`
-	SupportsVision bool   `json:"supports_vision"`
+	ID             string        `json:"id" yaml:"id" xml:"id"`
+	Description    string        `json:"description" yaml:"description" xml:"description"`
+	ContextWindow  int           `json:"context_window" yaml:"context_window" xml:"context_window"`
+	SupportsVision bool          `json:"supports_vision" yaml:"supports_vision" xml:"supports_vision"`
+	Pricing        *ModelPricing `json:"pricing,omitempty" yaml:"pricing,omitempty" xml:"pricing,omitempty"`
+}
+
+// ModelPricing is a model's price in USD per million tokens, for providers
+// whose catalog reports it.
+type ModelPricing struct {
+	Input  float64 `json:"input" yaml:"input" xml:"input"`
+	Output float64 `json:"output" yaml:"output" xml:"output"`
+}
+
+// ProviderMiddleware wraps a Provider to add cross-cutting behaviour such as
+// logging or timing without touching the individual provider implementations.
+type ProviderMiddleware func(Provider) Provider
+
+// Chain wraps p with the given middlewares. The first middleware is the
+// outermost one, so it sees the call first and the result last.
+func Chain(p Provider, mws ...ProviderMiddleware) Provider {
+	for i := len(mws) - 1; i >= 0; i-- {
+		p = mws[i](p)
+	}
+	return p
 }
@@ -0,0 +1,231 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// chatProviders are the providers that post OpenAI-shaped chat requests,
+// with the auth header each is expected to send for key "test-key".
+var chatProviders = []struct {
+	name       string
+	new        func(Config) Provider
+	authHeader string
+	authValue  string
+}{
+	{"openai", func(c Config) Provider { return NewOpenAI(c) }, "Authorization", "Bearer test-key"},
+	{"mistral", func(c Config) Provider { return NewMistral(c) }, "Authorization", "Bearer test-key"},
+	{"deepseek", func(c Config) Provider { return NewDeepSeek(c) }, "Authorization", "Bearer test-key"},
+	{"xai", func(c Config) Provider { return NewXAI(c) }, "Authorization", "Bearer test-key"},
+	{"custom", func(c Config) Provider {
+		c.AuthHeader = "X-Api-Key"
+		return NewCustom(c)
+	}, "X-Api-Key", "test-key"},
+}
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+func TestChatRequest(t *testing.T) {
+	for _, tc := range chatProviders {
+		t.Run(tc.name, func(t *testing.T) {
+			var got chatRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/chat/completions" {
+					t.Errorf("request = %s %s, want POST /chat/completions", r.Method, r.URL.Path)
+				}
+				if v := r.Header.Get(tc.authHeader); v != tc.authValue {
+					t.Errorf("%s = %q, want %q", tc.authHeader, v, tc.authValue)
+				}
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decoding body: %v", err)
+				}
+				w.Header().Set("X-Request-Id", "req-1")
+				w.Write([]byte(`{"model":"served-model","choices":[{"message":{"role":"assistant","content":"hello"}}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+			}))
+			defer srv.Close()
+
+			p := tc.new(Config{APIKey: "test-key", BaseURL: srv.URL, Model: "test-model", SystemPrompt: "be brief"})
+			result, err := p.Generate(context.Background(), Inputs{Prompt: "hi"})
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			if got.Model != "test-model" {
+				t.Errorf("model = %q, want test-model", got.Model)
+			}
+			want := []Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "hi"}}
+			if len(got.Messages) != len(want) {
+				t.Fatalf("messages = %+v, want %+v", got.Messages, want)
+			}
+			for i := range want {
+				if got.Messages[i] != want[i] {
+					t.Errorf("messages[%d] = %+v, want %+v", i, got.Messages[i], want[i])
+				}
+			}
+
+			if result.Content != "hello" || result.Model != "served-model" || result.RequestID != "req-1" {
+				t.Errorf("result = %+v", result)
+			}
+			if result.Usage == nil || result.Usage.TotalTokens != 4 {
+				t.Errorf("usage = %+v, want 4 total tokens", result.Usage)
+			}
+		})
+	}
+}
+
+func TestChatErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		provider string // only run for this provider; empty runs all
+		want     APIError
+		auth     bool
+		notFound bool
+		quota    bool
+	}{
+		{
+			name:     "openai error object",
+			status:   http.StatusNotFound,
+			body:     `{"error":{"message":"The model does not exist","code":"model_not_found","type":"invalid_request_error"}}`,
+			provider: "openai",
+			want:     APIError{StatusCode: 404, Message: "The model does not exist", Code: "model_not_found", Type: "invalid_request_error", RequestID: "req-err"},
+			notFound: true,
+		},
+		{
+			name:     "openai quota",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"message":"You exceeded your current quota","code":"insufficient_quota","type":"insufficient_quota"}}`,
+			provider: "openai",
+			want:     APIError{StatusCode: 429, Message: "You exceeded your current quota", Code: "insufficient_quota", Type: "insufficient_quota", RequestID: "req-err"},
+			quota:    true,
+		},
+		{
+			name:     "mistral message",
+			status:   http.StatusBadRequest,
+			body:     `{"message":"Invalid model: nope","type":"invalid_model"}`,
+			provider: "mistral",
+			want:     APIError{StatusCode: 400, Message: "Invalid model: nope", Type: "invalid_model", RequestID: "req-err"},
+			notFound: true,
+		},
+		{
+			name:     "deepseek message",
+			status:   http.StatusBadRequest,
+			body:     `{"message":"Model Not Exist"}`,
+			provider: "deepseek",
+			want:     APIError{StatusCode: 400, Message: "Model Not Exist", RequestID: "req-err"},
+			notFound: true,
+		},
+		{
+			name:     "deepseek error object",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"message":"Model Not Exist","type":"invalid_request_error","param":null,"code":"invalid_request_error"}}`,
+			provider: "deepseek",
+			want:     APIError{StatusCode: 400, Message: "Model Not Exist", Code: "invalid_request_error", Type: "invalid_request_error", RequestID: "req-err"},
+			notFound: true,
+		},
+		{
+			name:     "deepseek insufficient balance",
+			status:   http.StatusPaymentRequired,
+			body:     `{"error":{"message":"Insufficient Balance","type":"unknown_error","param":null,"code":"invalid_request_error"}}`,
+			provider: "deepseek",
+			want:     APIError{StatusCode: 402, Message: "Insufficient Balance", Code: "invalid_request_error", Type: "unknown_error", RequestID: "req-err"},
+			quota:    true,
+		},
+		{
+			name:   "plain text",
+			status: http.StatusUnauthorized,
+			body:   "unauthorized",
+			want:   APIError{StatusCode: 401, Message: "unauthorized", RequestID: "req-err"},
+			auth:   true,
+		},
+		{
+			name:   "html from a proxy",
+			status: http.StatusBadGateway,
+			body:   "<html><body>502 Bad Gateway</body></html>",
+			want:   APIError{StatusCode: 502, RequestID: "req-err"},
+		},
+	}
+
+	for _, tc := range tests {
+		for _, p := range chatProviders {
+			if tc.provider != "" && tc.provider != p.name {
+				continue
+			}
+			t.Run(tc.name+"/"+p.name, func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Request-Id", "req-err")
+					if tc.body[0] == '<' {
+						w.Header().Set("Content-Type", "text/html")
+					}
+					w.WriteHeader(tc.status)
+					w.Write([]byte(tc.body))
+				}))
+				defer srv.Close()
+
+				_, err := p.new(Config{APIKey: "test-key", BaseURL: srv.URL}).Generate(context.Background(), Inputs{Prompt: "hi"})
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("err = %v, want *APIError", err)
+				}
+				if apiErr.StatusCode != tc.want.StatusCode || apiErr.Code != tc.want.Code ||
+					apiErr.Type != tc.want.Type || apiErr.RequestID != tc.want.RequestID {
+					t.Errorf("err = %+v, want %+v", *apiErr, tc.want)
+				}
+				if tc.want.Message != "" && apiErr.Message != tc.want.Message {
+					t.Errorf("message = %q, want %q", apiErr.Message, tc.want.Message)
+				}
+				if tc.want.Message == "" && apiErr.Message == "" {
+					t.Errorf("message is empty")
+				}
+				if apiErr.IsAuth() != tc.auth || apiErr.IsModelNotFound() != tc.notFound || apiErr.IsQuotaExceeded() != tc.quota {
+					t.Errorf("IsAuth=%v IsModelNotFound=%v IsQuotaExceeded=%v, want %v %v %v",
+						apiErr.IsAuth(), apiErr.IsModelNotFound(), apiErr.IsQuotaExceeded(), tc.auth, tc.notFound, tc.quota)
+				}
+			})
+		}
+	}
+}
+
+func TestVisionModelDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		new   func(Config) Provider
+		model string
+		want  string
+	}{
+		{"together default", func(c Config) Provider { return NewTogether(c) }, "", togetherDefaultVisionModel},
+		{"together --model", func(c Config) Provider { return NewTogether(c) }, "Qwen/Qwen2.5-VL-72B-Instruct", "Qwen/Qwen2.5-VL-72B-Instruct"},
+		{"xai default", func(c Config) Provider { return NewXAI(c) }, "", xaiDefaultVisionModel},
+		{"openai ignores --model", func(c Config) Provider { return NewOpenAI(c) }, "gpt-4.1", openAIVisionModel},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got chatRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"a cat"}}]}`))
+			}))
+			defer srv.Close()
+
+			p := tc.new(Config{APIKey: "test-key", BaseURL: srv.URL, Model: tc.model})
+			inputs := Inputs{Prompt: "what is this?", Images: []FileInput{{Data: []byte("x"), Filename: "a.png"}}}
+			if _, err := p.Generate(context.Background(), inputs); err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if got.Model != tc.want {
+				t.Errorf("model = %q, want %q", got.Model, tc.want)
+			}
+		})
+	}
+}
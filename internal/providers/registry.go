@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Registration describes a provider to the registry. Each provider
+// registers itself from an init function in its own file.
+type Registration struct {
+	Name string
+	// Default providers are the ones models, ping and doctor check when
+	// --provider is not given.
+	Default bool
+
+	// APIKeyEnv is the environment variable holding the API key, or "" for
+	// providers that take none (exec, mock) or sign requests themselves
+	// (bedrock).
+	APIKeyEnv string
+	// SelfHosted providers run without a key by default and accept a
+	// user-supplied BaseURL and AuthHeader.
+	SelfHosted bool
+	// BaseURLEnv names the environment variable with the API root. When
+	// DefaultBaseURL is empty it is required.
+	BaseURLEnv string
+	// ModelEnv names the environment variable with the model to use when
+	// none is given. When DefaultModel is empty it is required.
+	ModelEnv string
+
+	DefaultModel string
+	// DefaultVisionModel serves requests with images; empty means
+	// DefaultModel.
+	DefaultVisionModel string
+	// DefaultBaseURL is the public API root, or "" when there is none.
+	DefaultBaseURL string
+
+	New func(Config) Provider
+}
+
+// Supports reports whether the provider has feature, independent of its
+// configuration.
+func (r Registration) Supports(feature Feature) bool {
+	return r.New(Config{}).Supports(feature)
+}
+
+// ListsModels reports whether the provider implements ModelLister.
+func (r Registration) ListsModels() bool {
+	_, ok := r.New(Config{}).(ModelLister)
+	return ok
+}
+
+var registry = map[string]Registration{}
+
+// Register adds a provider. It panics on a duplicate name, which is a
+// programming error.
+func Register(r Registration) {
+	if _, ok := registry[r.Name]; ok {
+		panic("providers: " + r.Name + " registered twice")
+	}
+	registry[r.Name] = r
+}
+
+// Lookup returns the registration for name.
+func Lookup(name string) (Registration, bool) {
+	r, ok := registry[name]
+	return r, ok
+}
+
+// Names returns every registered provider name, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Defaults returns the names of the default providers, sorted.
+func Defaults() []string {
+	var names []string
+	for _, name := range Names() {
+		if registry[name].Default {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// New builds the named provider.
+func New(name string, config Config) (Provider, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+	return r.New(config), nil
+}
+
+// NewModelLister builds the named provider for listing its models.
+func NewModelLister(name string, config Config) (ModelLister, error) {
+	p, err := New(name, config)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := p.(ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%s can't list models", name)
+	}
+	return lister, nil
+}
+
+// DefaultModel returns the model a provider uses when Config.Model is empty,
+// or "" if the provider has no built-in default.
+func DefaultModel(provider string) string {
+	return registry[provider].DefaultModel
+}
+
+// DefaultBaseURL returns the API endpoint a provider talks to when
+// Config.BaseURL is empty, or "" for providers without a public one.
+func DefaultBaseURL(provider string) string {
+	return registry[provider].DefaultBaseURL
+}
+
+// DefaultVisionModel returns the model a provider uses for requests with
+// images. OpenAI sends all of them to its vision model regardless of
+// Config.Model; xAI uses its vision model when Config.Model is empty;
+// other providers use their default model.
+func DefaultVisionModel(provider string) string {
+	r := registry[provider]
+	if r.DefaultVisionModel != "" {
+		return r.DefaultVisionModel
+	}
+	return r.DefaultModel
+}
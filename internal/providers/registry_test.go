@@ -0,0 +1,31 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	want := []string{"deepseek", "mistral", "openai"}
+	if got := Defaults(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Defaults() = %v, want %v", got, want)
+	}
+	for _, name := range Defaults() {
+		if r, _ := Lookup(name); !r.ListsModels() {
+			t.Errorf("default provider %s can't list models", name)
+		}
+	}
+}
+
+func TestDefaultVisionModel(t *testing.T) {
+	for provider, want := range map[string]string{
+		"openai":   openAIVisionModel,
+		"xai":      xaiDefaultVisionModel,
+		"together": togetherDefaultVisionModel,
+		"deepseek": deepseekDefaultModel,
+	} {
+		if got := DefaultVisionModel(provider); got != want {
+			t.Errorf("DefaultVisionModel(%q) = %q, want %q", provider, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Empty responses are usually transient, so they get a couple of quick
+// retries before ErrNoContent is surfaced.
+const (
+	emptyResponseRetries = 2
+	emptyResponseDelay   = 500 * time.Millisecond
+)
+
+// retryBudget is the number of retries left for this process, shared by
+// every provider; negative means unlimited.
+var retryBudget = struct {
+	sync.Mutex
+	remaining int
+}{remaining: -1}
+
+// SetRetryBudget caps the total retries across all requests in this
+// process. Once it is spent, failing requests return their error at once
+// instead of retrying, so a dead endpoint can't multiply the run time.
+// n < 0 removes the cap.
+func SetRetryBudget(n int) {
+	retryBudget.Lock()
+	defer retryBudget.Unlock()
+	retryBudget.remaining = n
+}
+
+// takeRetry reserves one retry from the budget, reporting false when none
+// are left.
+func takeRetry() bool {
+	retryBudget.Lock()
+	defer retryBudget.Unlock()
+	if retryBudget.remaining < 0 {
+		return true
+	}
+	if retryBudget.remaining == 0 {
+		return false
+	}
+	retryBudget.remaining--
+	return true
+}
+
+// WithEmptyResponseRetry repeats a Generate call that failed with
+// ErrNoContent, drawing each retry from the shared retry budget.
+func WithEmptyResponseRetry() ProviderMiddleware {
+	return func(next Provider) Provider {
+		return &generateFunc{
+			Provider: next,
+			generate: func(ctx context.Context, inputs Inputs) (Result, error) {
+				result, err := next.Generate(ctx, inputs)
+				for i := 0; i < emptyResponseRetries && errors.Is(err, ErrNoContent); i++ {
+					if !takeRetry() {
+						break
+					}
+					select {
+					case <-ctx.Done():
+						return result, err
+					case <-time.After(emptyResponseDelay):
+					}
+					result, err = next.Generate(ctx, inputs)
+				}
+				return result, err
+			},
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubProvider returns its results in order, one per Generate call.
+type stubProvider struct {
+	results []Result
+	errs    []error
+	calls   int
+}
+
+func (s *stubProvider) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	i := s.calls
+	s.calls++
+	if i >= len(s.results) {
+		i = len(s.results) - 1
+	}
+	return s.results[i], s.errs[i]
+}
+
+func (s *stubProvider) Supports(feature Feature) bool { return true }
+
+func TestEmptyResponseRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		stub      *stubProvider
+		wantCalls int
+		want      string
+		wantErr   error
+	}{
+		{
+			name: "empty then good",
+			stub: &stubProvider{
+				results: []Result{{}, {Content: "second"}},
+				errs:    []error{ErrNoContent, nil},
+			},
+			wantCalls: 2,
+			want:      "second",
+		},
+		{
+			name: "always empty",
+			stub: &stubProvider{
+				results: []Result{{}},
+				errs:    []error{ErrNoContent},
+			},
+			wantCalls: 1 + emptyResponseRetries,
+			wantErr:   ErrNoContent,
+		},
+		{
+			name: "other errors are not retried",
+			stub: &stubProvider{
+				results: []Result{{}},
+				errs:    []error{&APIError{StatusCode: 500}},
+			},
+			wantCalls: 1,
+			wantErr:   &APIError{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := WithEmptyResponseRetry()(tc.stub).Generate(context.Background(), Inputs{Prompt: "hi"})
+			if tc.stub.calls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", tc.stub.calls, tc.wantCalls)
+			}
+			switch want := tc.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+			case *APIError:
+				if !errors.As(err, &want) {
+					t.Fatalf("err = %v, want *APIError", err)
+				}
+			default:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+			}
+			if result.Content != tc.want {
+				t.Errorf("content = %q, want %q", result.Content, tc.want)
+			}
+		})
+	}
+}
+
+func TestEmptyResponseRetryBudget(t *testing.T) {
+	SetRetryBudget(0)
+	defer SetRetryBudget(-1)
+
+	stub := &stubProvider{results: []Result{{}, {Content: "never"}}, errs: []error{ErrNoContent, nil}}
+	_, err := WithEmptyResponseRetry()(stub).Generate(context.Background(), Inputs{Prompt: "hi"})
+	if !errors.Is(err, ErrNoContent) || stub.calls != 1 {
+		t.Errorf("err = %v after %d calls, want ErrNoContent after 1", err, stub.calls)
+	}
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/*
+=== Together AI ===
+Open-weight models (Llama, Qwen, DeepSeek, Mixtral, ...) behind an
+OpenAI-compatible chat API. Model IDs carry the organization, e.g.
+meta-llama/Llama-3.3-70B-Instruct-Turbo or Qwen/Qwen2.5-72B-Instruct-Turbo.
+Vision works with the vision models (Llama-3.2-*-Vision, Qwen2.5-VL, Llama 4).
+*/
+
+const (
+	togetherBaseURL            = "https://api.together.xyz/v1"
+	togetherDefaultModel       = "meta-llama/Llama-3.3-70B-Instruct-Turbo"
+	togetherDefaultVisionModel = "meta-llama/Llama-3.2-11B-Vision-Instruct-Turbo"
+)
+
+type Together struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:               "together",
+		APIKeyEnv:          "TOGETHER_API_KEY",
+		DefaultModel:       togetherDefaultModel,
+		DefaultVisionModel: togetherDefaultVisionModel,
+		DefaultBaseURL:     togetherBaseURL,
+		New:                func(config Config) Provider { return NewTogether(config) },
+	})
+}
+
+func NewTogether(config Config) *Together {
+	return &Together{openai: newOpenAICompatible(config, openAIService{
+		name:               "together",
+		baseURL:            togetherBaseURL,
+		defaultModel:       togetherDefaultModel,
+		defaultVisionModel: togetherDefaultVisionModel,
+	})}
+}
+
+func (p *Together) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration || feature == FeatureVision
+}
+
+func (p *Together) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
+
+// TogetherModel is one entry of Together's /models list, which is a bare
+// JSON array rather than OpenAI's {"data": [...]}.
+type TogetherModel struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"` // chat, language, image, embedding, ...
+	DisplayName   string `json:"display_name"`
+	Organization  string `json:"organization"`
+	ContextLength int    `json:"context_length"`
+	Pricing       struct {
+		Input  float64 `json:"input"` // USD per million tokens
+		Output float64 `json:"output"`
+	} `json:"pricing"`
+}
+
+// ListModels returns the chat models; image, embedding and other model
+// types can't serve chat completions.
+func (p *Together) ListModels(ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	err := fetchPages(ctx, p.openai.client, p.openai.config, baseURL(p.openai.config, togetherBaseURL)+"/models", p.openai.setAuthHeaders, func(body []byte) (string, error) {
+		var response []TogetherModel
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
+		for _, m := range response {
+			if m.Type != "chat" {
+				continue
+			}
+			id := strings.ToLower(m.ID)
+			models = append(models, Model{
+				ID:             m.ID,
+				Description:    fmt.Sprintf("%s (%s)", m.DisplayName, m.Organization),
+				ContextWindow:  m.ContextLength,
+				SupportsVision: strings.Contains(id, "vision") || strings.Contains(id, "-vl") || strings.Contains(id, "llama-4"),
+				Pricing:        &ModelPricing{Input: m.Pricing.Input, Output: m.Pricing.Output},
+			})
+		}
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
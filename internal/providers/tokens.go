@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tiktoken-go/tokenizer"
+)
+
+// charsPerToken is the rule-of-thumb ratio used when no real tokenizer is
+// available for a model.
+const charsPerToken = 4
+
+// CountTokens returns the number of tokens text uses for model. OpenAI models
+// are counted with their tiktoken encoding; other models fall back to
+// EstimateTokens, in which case exact is false.
+func CountTokens(model, text string) (count int, exact bool) {
+	if codec, err := tokenizer.ForModel(tokenizer.Model(model)); err == nil {
+		if n, err := codec.Count(text); err == nil {
+			return n, true
+		}
+	}
+	return EstimateTokens(text), false
+}
+
+// EstimateTokens approximates the token count of text from its length.
+func EstimateTokens(text string) int {
+	if strings.TrimSpace(text) == "" {
+		return 0
+	}
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Vision token pricing from the OpenAI header comment: a flat base per image
+// plus a charge per 512px tile at high detail.
+const (
+	visionBaseTokens = 85
+	visionTileTokens = 170
+	visionTileSize   = 512
+)
+
+// EstimateVisionTokens returns the input tokens OpenAI charges for an image
+// of the given size. Low detail is a flat base cost. Otherwise the image is
+// scaled to fit 2048x2048, then its short side to 768px, and each 512px tile
+// is charged on top of the base. "auto" is priced as high, the upper bound.
+func EstimateVisionTokens(width, height int, detail string) int {
+	if detail == "low" {
+		return visionBaseTokens
+	}
+	if width <= 0 || height <= 0 {
+		return visionBaseTokens
+	}
+
+	w, h := float64(width), float64(height)
+	if long := max(w, h); long > 2048 {
+		w, h = w*2048/long, h*2048/long
+	}
+	if short := min(w, h); short > 768 {
+		w, h = w*768/short, h*768/short
+	}
+
+	tiles := int(math.Ceil(w/visionTileSize) * math.Ceil(h/visionTileSize))
+	return visionBaseTokens + visionTileTokens*tiles
+}
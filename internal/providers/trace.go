@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// traceTransport logs connection-level timings for each request: DNS,
+// TCP connect, TLS handshake, whether a pooled connection was reused and
+// the time to the first response byte.
+type traceTransport struct {
+	base http.RoundTripper
+	w    io.Writer
+}
+
+func (t traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	start := time.Now()
+	var dnsStart, connectStart, tlsStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			if info.Err != nil {
+				fmt.Fprintf(t.w, "[TRACE] %s: dns failed after %s: %v\n", host, time.Since(dnsStart), info.Err)
+				return
+			}
+			fmt.Fprintf(t.w, "[TRACE] %s: dns %s\n", host, time.Since(dnsStart))
+		},
+		ConnectStart: func(network, addr string) { connectStart = time.Now() },
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				fmt.Fprintf(t.w, "[TRACE] %s: connect %s failed after %s: %v\n", host, addr, time.Since(connectStart), err)
+				return
+			}
+			fmt.Fprintf(t.w, "[TRACE] %s: connect %s %s\n", host, addr, time.Since(connectStart))
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err != nil {
+				fmt.Fprintf(t.w, "[TRACE] %s: tls handshake failed after %s: %v\n", host, time.Since(tlsStart), err)
+				return
+			}
+			fmt.Fprintf(t.w, "[TRACE] %s: tls handshake %s (%s)\n", host, time.Since(tlsStart), tls.VersionName(state.Version))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				fmt.Fprintf(t.w, "[TRACE] %s: reused connection (idle %s)\n", host, info.IdleTime)
+				return
+			}
+			fmt.Fprintf(t.w, "[TRACE] %s: new connection\n", host)
+		},
+		GotFirstResponseByte: func() {
+			fmt.Fprintf(t.w, "[TRACE] %s: first response byte after %s\n", host, time.Since(start))
+		},
+	}
+
+	return t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+=== vLLM ===
+vLLM's OpenAI-compatible server (vllm serve MODEL), on localhost:8000 by
+default. It serves a single model, found through /v1/models like the other
+local servers. On top of the OpenAI fields it accepts its own sampling and
+guided decoding parameters, set with Config.VLLM.
+*/
+
+const vllmBaseURL = "http://localhost:8000/v1"
+
+// VLLMOptions are vLLM-only request parameters. Zero values are left out
+// so the server's defaults apply.
+type VLLMOptions struct {
+	BestOf            int     `json:"best_of,omitempty" yaml:"best_of"`
+	UseBeamSearch     bool    `json:"use_beam_search,omitempty" yaml:"use_beam_search"`
+	LengthPenalty     float64 `json:"length_penalty,omitempty" yaml:"length_penalty"`
+	TopK              int     `json:"top_k,omitempty" yaml:"top_k"`
+	MinP              float64 `json:"min_p,omitempty" yaml:"min_p"`
+	RepetitionPenalty float64 `json:"repetition_penalty,omitempty" yaml:"repetition_penalty"`
+
+	// Guided decoding constrains the output; at most one may be set.
+	GuidedJSON    any      `json:"guided_json,omitempty" yaml:"guided_json"` // JSON Schema
+	GuidedRegex   string   `json:"guided_regex,omitempty" yaml:"guided_regex"`
+	GuidedChoice  []string `json:"guided_choice,omitempty" yaml:"guided_choice"`
+	GuidedGrammar string   `json:"guided_grammar,omitempty" yaml:"guided_grammar"` // EBNF
+}
+
+// Validate rejects combinations vLLM would refuse.
+func (o VLLMOptions) Validate() error {
+	guided := 0
+	for _, set := range []bool{o.GuidedJSON != nil, o.GuidedRegex != "", len(o.GuidedChoice) > 0, o.GuidedGrammar != ""} {
+		if set {
+			guided++
+		}
+	}
+	if guided > 1 {
+		return fmt.Errorf("only one of guided_json, guided_regex, guided_choice and guided_grammar can be set")
+	}
+	if o.BestOf < 0 {
+		return fmt.Errorf("best_of must not be negative")
+	}
+	if o.UseBeamSearch && o.BestOf == 1 {
+		return fmt.Errorf("use_beam_search needs best_of of at least 2 (the beam width)")
+	}
+	return nil
+}
+
+// params merges the options under extra, so --extra-params still wins.
+func (o VLLMOptions) params(extra map[string]any) map[string]any {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return extra
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil || len(params) == 0 {
+		return extra
+	}
+	for key, value := range extra {
+		params[key] = value
+	}
+	return params
+}
+
+func init() {
+	Register(Registration{
+		Name:           "vllm",
+		APIKeyEnv:      "VLLM_API_KEY",
+		SelfHosted:     true,
+		BaseURLEnv:     "VLLM_BASE_URL",
+		DefaultBaseURL: vllmBaseURL,
+		New:            func(config Config) Provider { return NewVLLM(config) },
+	})
+}
+
+// NewVLLM talks to a vLLM server, sending Config.VLLM with every request.
+func NewVLLM(config Config) *Local {
+	config.ExtraParams = config.VLLM.params(config.ExtraParams)
+	return newLocal(config, openAIService{name: "vllm", baseURL: vllmBaseURL, optionalAuth: true})
+}
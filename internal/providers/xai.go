@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+=== xAI ===
+Grok models behind an OpenAI-compatible API at api.x.ai.
+
+- grok-3: default, text (131K context)
+- grok-3-mini: fast reasoning, text (131K context)
+- grok-4: flagship, text and images (256K context)
+- grok-2-vision-latest: images, used for vision requests without --model (32K context)
+*/
+
+const (
+	xaiBaseURL            = "https://api.x.ai/v1"
+	xaiDefaultModel       = "grok-3"
+	xaiDefaultVisionModel = "grok-2-vision-latest"
+)
+
+type XAI struct {
+	openai *OpenAI
+}
+
+func init() {
+	Register(Registration{
+		Name:               "xai",
+		APIKeyEnv:          "XAI_API_KEY",
+		DefaultModel:       xaiDefaultModel,
+		DefaultVisionModel: xaiDefaultVisionModel,
+		DefaultBaseURL:     xaiBaseURL,
+		New:                func(config Config) Provider { return NewXAI(config) },
+	})
+}
+
+func NewXAI(config Config) *XAI {
+	return &XAI{openai: newOpenAICompatible(config, openAIService{
+		name:               "xai",
+		baseURL:            xaiBaseURL,
+		defaultModel:       xaiDefaultModel,
+		defaultVisionModel: xaiDefaultVisionModel,
+	})}
+}
+
+func (p *XAI) Supports(feature Feature) bool {
+	return feature == FeatureTextGeneration || feature == FeatureVision
+}
+
+func (p *XAI) Generate(ctx context.Context, inputs Inputs) (Result, error) {
+	return p.openai.Generate(ctx, inputs)
+}
+
+type XAILanguageModelsResponse struct {
+	Models []struct {
+		ID              string   `json:"id"`
+		OwnedBy         string   `json:"owned_by"`
+		InputModalities []string `json:"input_modalities"`
+	} `json:"models"`
+}
+
+// ListModels uses /language-models rather than /models because it also
+// reports which models accept images.
+func (p *XAI) ListModels(ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	err := fetchPages(ctx, p.openai.client, p.openai.config, baseURL(p.openai.config, xaiBaseURL)+"/language-models", p.openai.setAuthHeaders, func(body []byte) (string, error) {
+		var response XAILanguageModelsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", fmt.Errorf("response parsing failed: %w", err)
+		}
+		for _, m := range response.Models {
+			model := Model{ID: m.ID, Description: fmt.Sprintf("%s (%s)", m.ID, m.OwnedBy)}
+			for _, modality := range m.InputModalities {
+				model.SupportsVision = model.SupportsVision || modality == "image"
+			}
+			models = append(models, model)
+		}
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}